@@ -0,0 +1,76 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// DownloadConfig returns the system configuration backup as a stream, and the caller must close it.
+// The size and checksum are verified at the end of the stream, a corrupted download fails with ErrChecksumMismatch.
+// GET /rest/v2/system/config/backup
+func (s *SystemOp) DownloadConfig(ctx context.Context, progress ProgressFunc) (io.ReadCloser, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/config/backup", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.SendRawRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newVerifyReader(res, progress), nil
+}
+
+// RestoreConfig uploads a configuration backup saved by DownloadConfig and restores it.
+// size is the length of r, or -1 if unknown. If r is an io.ReadSeeker, it is sent again after the access token is renewed.
+// POST /rest/v2/system/config/restore
+func (s *SystemOp) RestoreConfig(ctx context.Context, r io.Reader, size int64, progress ProgressFunc) error {
+	client, err := s.auth()
+	if err != nil {
+		return err
+	}
+	// A streaming body cannot be sent again, so make sure the access token is fresh first.
+	if _, err := s.GetMaintenanceMode(ctx); err != nil {
+		return err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/config/restore", newProgressReader(r, size, progress))
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	// A seekable backup can still be sent again if the token expires during the upload
+	if rs, ok := r.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			if _, err := rs.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(newProgressReader(rs, size, progress)), nil
+		}
+	}
+
+	res, err := client.SendRawRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data := EmptyData{}
+	return json.NewDecoder(res.Body).Decode(&data)
+}
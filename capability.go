@@ -0,0 +1,205 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned when the firmware of the storage does not support a feature
+var ErrUnsupported = errors.New("feature is not supported")
+
+// Feature is an optional function which depends on the firmware version
+type Feature string
+
+const (
+	FeatureQoS            Feature = "QoS"
+	FeatureClone          Feature = "Clone"
+	FeatureSnapshotExpose Feature = "SnapshotExpose"
+	FeatureFCPatch        Feature = "FCPatch"
+)
+
+// DefaultFeatureMinFirmware is the minimum firmware version of each feature used by NewCapabilities.
+// Use NewCapabilitiesWithMatrix if a model line needs other versions.
+var DefaultFeatureMinFirmware = map[Feature]string{
+	FeatureQoS:            "1.1.0",
+	FeatureClone:          "1.2.0",
+	FeatureSnapshotExpose: "1.2.0",
+	FeatureFCPatch:        "1.3.0",
+}
+
+// FirmwareVersion is a parsed firmware version, e.g. "1.2.3", "1.2.3.0456" or "v1.2.3-beta"
+type FirmwareVersion struct {
+	Major int
+	Minor int
+	Patch int
+	Build int
+	Pre   string
+	Raw   string
+
+	hasBuild bool // the build number is given, e.g. "1.2.3.0456"
+}
+
+// ParseFirmwareVersion parses the FirmwareVer of AboutData.
+// A leading non-numeric prefix such as "v" or "XEVO " is ignored.
+func ParseFirmwareVersion(s string) (FirmwareVersion, error) {
+	ver := FirmwareVersion{Raw: s}
+
+	str := strings.TrimSpace(s)
+	start := strings.IndexFunc(str, func(r rune) bool { return r >= '0' && r <= '9' })
+	if start < 0 {
+		return ver, fmt.Errorf("invalid firmware version %q", s)
+	}
+	str = str[start:]
+	// Ignore a trailing description, e.g. "1.2.3 (build 0456)"
+	if i := strings.IndexAny(str, " ("); i >= 0 {
+		str = str[:i]
+	}
+	if i := strings.Index(str, "-"); i >= 0 {
+		ver.Pre = str[i+1:]
+		str = str[:i]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) > 4 {
+		return ver, fmt.Errorf("invalid firmware version %q", s)
+	}
+	nums := []*int{&ver.Major, &ver.Minor, &ver.Patch, &ver.Build}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return ver, fmt.Errorf("invalid firmware version %q", s)
+		}
+		*nums[i] = n
+	}
+	ver.hasBuild = len(parts) == 4
+
+	return ver, nil
+}
+
+// Compare returns -1, 0 or 1 if v is older, equal or newer than o.
+// A pre-release is older than the release of the same number.
+func (v FirmwareVersion) Compare(o FirmwareVersion) int {
+	a := []int{v.Major, v.Minor, v.Patch, v.Build}
+	b := []int{o.Major, o.Minor, o.Patch, o.Build}
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	case v.Pre < o.Pre:
+		return -1
+	}
+	return 1
+}
+
+// Matches returns true if v is the same release as o. Major, minor, patch and pre-release must be equal,
+// and the build number is only compared if both versions give it, so "1.3.0.0123" matches "1.3.0".
+func (v FirmwareVersion) Matches(o FirmwareVersion) bool {
+	if v.Major != o.Major || v.Minor != o.Minor || v.Patch != o.Patch || v.Pre != o.Pre {
+		return false
+	}
+	return !v.hasBuild || !o.hasBuild || v.Build == o.Build
+}
+
+// AtLeast returns true if v is equal to or newer than o
+func (v FirmwareVersion) AtLeast(o FirmwareVersion) bool {
+	return v.Compare(o) >= 0
+}
+
+func (v FirmwareVersion) String() string {
+	str := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Build != 0 {
+		str += fmt.Sprintf(".%d", v.Build)
+	}
+	if v.Pre != "" {
+		str += "-" + v.Pre
+	}
+	return str
+}
+
+// Capabilities is the feature set of the storage
+type Capabilities struct {
+	FirmwareVer FirmwareVersion
+	ModelName   string
+	ModelType   string
+	Features    map[Feature]bool
+	// MinFirmware is the minimum firmware version of each feature
+	MinFirmware map[Feature]string
+}
+
+// NewCapabilities returns capabilities from system information with DefaultFeatureMinFirmware
+func NewCapabilities(about *AboutData) (*Capabilities, error) {
+	return NewCapabilitiesWithMatrix(about, DefaultFeatureMinFirmware)
+}
+
+// NewCapabilitiesWithMatrix returns capabilities from system information.
+// matrix is the minimum firmware version of each feature, features not in matrix are unsupported.
+func NewCapabilitiesWithMatrix(about *AboutData, matrix map[Feature]string) (*Capabilities, error) {
+	ver, err := ParseFirmwareVersion(about.FirmwareVer)
+	if err != nil {
+		return nil, err
+	}
+
+	caps := &Capabilities{
+		FirmwareVer: ver,
+		ModelName:   about.ModelName,
+		ModelType:   about.ModelType,
+		Features:    map[Feature]bool{},
+		MinFirmware: map[Feature]string{},
+	}
+	for f, min := range matrix {
+		minVer, err := ParseFirmwareVersion(min)
+		if err != nil {
+			return nil, fmt.Errorf("minimum firmware of %s: %w", f, err)
+		}
+		caps.Features[f] = ver.AtLeast(minVer)
+		caps.MinFirmware[f] = min
+	}
+
+	return caps, nil
+}
+
+// GetCapabilities probes the features supported by the storage
+func (s *SystemOp) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	about, err := s.GetAbout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCapabilities(about)
+}
+
+// Supports returns true if the feature is supported
+func (c *Capabilities) Supports(f Feature) bool {
+	return c.Features[f]
+}
+
+// Require returns ErrUnsupported if the feature is not supported.
+// A nil Capabilities means unknown, and every feature is allowed.
+func (c *Capabilities) Require(f Feature) error {
+	if c == nil || c.Supports(f) {
+		return nil
+	}
+
+	min, ok := c.MinFirmware[f]
+	if !ok {
+		return fmt.Errorf("%w: %s (%s %s)", ErrUnsupported, f, c.ModelName, c.FirmwareVer.Raw)
+	}
+	return fmt.Errorf("%w: %s requires firmware %s or later (%s %s)", ErrUnsupported, f, min, c.ModelName, c.FirmwareVer.Raw)
+}
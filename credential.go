@@ -0,0 +1,200 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Credentials is the user name and password used to login QSAN storage.
+type Credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// CredentialProvider provides credentials whenever AuthClient has to login.
+// Implementations should return the latest credentials on every call,
+// so that a rotated password takes effect on the next login.
+type CredentialProvider interface {
+	Retrieve(ctx context.Context) (*Credentials, error)
+}
+
+// StaticCredentials provides fixed credentials.
+// Use SetPassword to change the password once the provider is used by a client.
+type StaticCredentials struct {
+	Credentials
+
+	mu sync.Mutex
+}
+
+// NewStaticCredentials returns a provider with fixed user and password
+func NewStaticCredentials(user, passwd string) *StaticCredentials {
+	return &StaticCredentials{Credentials: Credentials{User: user, Password: passwd}}
+}
+
+// Retrieve returns the fixed credentials
+func (p *StaticCredentials) Retrieve(ctx context.Context) (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.User == "" {
+		return nil, errors.New("static credentials: empty user")
+	}
+
+	creds := p.Credentials
+	return &creds, nil
+}
+
+// SetPassword replaces the password returned by later Retrieve calls
+func (p *StaticCredentials) SetPassword(passwd string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Password = passwd
+}
+
+// EnvCredentials reads credentials from environment variables.
+type EnvCredentials struct {
+	UserEnv     string
+	PasswordEnv string
+}
+
+// NewEnvCredentials returns a provider reading the given environment variables.
+// Empty names default to QSAN_USERNAME and QSAN_PASSWORD.
+func NewEnvCredentials(userEnv, passwdEnv string) *EnvCredentials {
+	if userEnv == "" {
+		userEnv = "QSAN_USERNAME"
+	}
+	if passwdEnv == "" {
+		passwdEnv = "QSAN_PASSWORD"
+	}
+
+	return &EnvCredentials{UserEnv: userEnv, PasswordEnv: passwdEnv}
+}
+
+// Retrieve reads the environment variables on every call
+func (p *EnvCredentials) Retrieve(ctx context.Context) (*Credentials, error) {
+	user := os.Getenv(p.UserEnv)
+	if user == "" {
+		return nil, fmt.Errorf("env credentials: %s is not set", p.UserEnv)
+	}
+
+	return &Credentials{User: user, Password: os.Getenv(p.PasswordEnv)}, nil
+}
+
+// FileCredentials reads credentials from files, e.g. a mounted Kubernetes secret.
+// Files are re-read when their modification time or size changes.
+type FileCredentials struct {
+	UserFile     string
+	PasswordFile string
+
+	mu      sync.Mutex
+	creds   *Credentials
+	userSt  fileStamp
+	passwSt fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials returns a provider reading user and password from the given files
+func NewFileCredentials(userFile, passwdFile string) *FileCredentials {
+	return &FileCredentials{UserFile: userFile, PasswordFile: passwdFile}
+}
+
+// Retrieve returns the cached credentials, or re-reads the files if they changed
+func (p *FileCredentials) Retrieve(ctx context.Context) (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	userSt, err := statFile(p.UserFile)
+	if err != nil {
+		return nil, fmt.Errorf("file credentials: %v", err)
+	}
+	passwSt, err := statFile(p.PasswordFile)
+	if err != nil {
+		return nil, fmt.Errorf("file credentials: %v", err)
+	}
+
+	if p.creds != nil && userSt == p.userSt && passwSt == p.passwSt {
+		creds := *p.creds
+		return &creds, nil
+	}
+
+	user, err := os.ReadFile(p.UserFile)
+	if err != nil {
+		return nil, fmt.Errorf("file credentials: %v", err)
+	}
+	passwd, err := os.ReadFile(p.PasswordFile)
+	if err != nil {
+		return nil, fmt.Errorf("file credentials: %v", err)
+	}
+
+	glog.V(3).Infof("[FileCredentials] credentials reloaded from %s and %s\n", p.UserFile, p.PasswordFile)
+	p.creds = &Credentials{
+		User:     strings.TrimSpace(string(user)),
+		Password: strings.TrimRight(string(passwd), "\r\n"),
+	}
+	p.userSt, p.passwSt = userSt, passwSt
+
+	creds := *p.creds
+	return &creds, nil
+}
+
+func statFile(name string) (fileStamp, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}, err
+	}
+
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+// ExecCredentials runs an external command which prints credentials as JSON
+// to stdout, e.g. {"user": "admin", "password": "1234"}.
+type ExecCredentials struct {
+	Command string
+	Args    []string
+	Env     []string
+}
+
+// NewExecCredentials returns a provider running the given command
+func NewExecCredentials(command string, args ...string) *ExecCredentials {
+	return &ExecCredentials{Command: command, Args: args}
+}
+
+// Retrieve runs the command on every call
+func (p *ExecCredentials) Retrieve(ctx context.Context) (*Credentials, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Env = append(os.Environ(), p.Env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("exec credentials: %s failed: %v: %s", p.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	creds := Credentials{}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("exec credentials: invalid output of %s: %v", p.Command, err)
+	}
+	if creds.User == "" {
+		return nil, fmt.Errorf("exec credentials: %s returned empty user", p.Command)
+	}
+
+	return &creds, nil
+}
@@ -0,0 +1,146 @@
+package goqsan
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStaticCredentials(t *testing.T) {
+	cp := NewStaticCredentials("admin", "1234")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			cp.Retrieve(context.Background())
+		}
+	}()
+	cp.SetPassword("5678")
+	<-done
+
+	creds, err := cp.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.User != "admin" || creds.Password != "5678" {
+		t.Fatalf("Retrieve after SetPassword returned %+v", creds)
+	}
+
+	if _, err := NewStaticCredentials("", "1234").Retrieve(context.Background()); err == nil {
+		t.Fatalf("Retrieve without user should fail")
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("GOQSAN_TEST_USER", "admin")
+	t.Setenv("GOQSAN_TEST_PASSWD", "1234")
+
+	cp := NewEnvCredentials("GOQSAN_TEST_USER", "GOQSAN_TEST_PASSWD")
+	creds, err := cp.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.User != "admin" || creds.Password != "1234" {
+		t.Fatalf("Retrieve returned %+v", creds)
+	}
+
+	// Rotated password is read on the next call
+	t.Setenv("GOQSAN_TEST_PASSWD", "5678")
+	if creds, _ = cp.Retrieve(context.Background()); creds.Password != "5678" {
+		t.Fatalf("Retrieve after rotation returned %+v", creds)
+	}
+
+	t.Setenv("GOQSAN_TEST_USER", "")
+	if _, err := cp.Retrieve(context.Background()); err == nil {
+		t.Fatalf("Retrieve without user should fail")
+	}
+
+	if cp := NewEnvCredentials("", ""); cp.UserEnv != "QSAN_USERNAME" || cp.PasswordEnv != "QSAN_PASSWORD" {
+		t.Fatalf("NewEnvCredentials defaults are %+v", cp)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "username")
+	passwdFile := filepath.Join(dir, "password")
+	writeFile := func(name, data string, mtime time.Time) {
+		if err := os.WriteFile(name, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	writeFile(userFile, "admin\n", now)
+	writeFile(passwdFile, "1234\n", now)
+
+	cp := NewFileCredentials(userFile, passwdFile)
+	creds, err := cp.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.User != "admin" || creds.Password != "1234" {
+		t.Fatalf("Retrieve returned %+v", creds)
+	}
+
+	// Same size, only the modification time changes
+	writeFile(passwdFile, "5678\n", now.Add(time.Minute))
+	if creds, _ = cp.Retrieve(context.Background()); creds.Password != "5678" {
+		t.Fatalf("Retrieve after rotation returned %+v", creds)
+	}
+
+	// Different size with the same modification time
+	writeFile(passwdFile, "rotated-again\n", now.Add(time.Minute))
+	if creds, _ = cp.Retrieve(context.Background()); creds.Password != "rotated-again" {
+		t.Fatalf("Retrieve after second rotation returned %+v", creds)
+	}
+
+	os.Remove(userFile)
+	if _, err := cp.Retrieve(context.Background()); err == nil {
+		t.Fatalf("Retrieve without user file should fail")
+	}
+}
+
+func TestExecCredentials(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	tests := []struct {
+		script string
+		user   string
+		passwd string
+		fail   bool
+	}{
+		{`echo '{"user": "admin", "password": "1234"}'`, "admin", "1234", false},
+		{`echo '{"user": "admin", "password": "'$GOQSAN_TEST_PASSWD'"}'`, "admin", "from-env", false},
+		{`echo 'not json'`, "", "", true},
+		{`echo '{"password": "1234"}'`, "", "", true},
+		{`echo failed >&2; exit 1`, "", "", true},
+	}
+
+	for _, tt := range tests {
+		cp := NewExecCredentials("sh", "-c", tt.script)
+		cp.Env = []string{"GOQSAN_TEST_PASSWD=from-env"}
+		creds, err := cp.Retrieve(context.Background())
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s: Retrieve should fail, returned %+v", tt.script, creds)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Retrieve failed: %v", tt.script, err)
+			continue
+		}
+		if creds.User != tt.user || creds.Password != tt.passwd {
+			t.Errorf("%s: Retrieve returned %+v", tt.script, creds)
+		}
+	}
+}
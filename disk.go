@@ -0,0 +1,149 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// DiskOp handles physical disk related methods of the QSAN storage.
+type DiskOp struct {
+	client *AuthClient
+}
+
+// DiskMediaType is the media type of a disk
+type DiskMediaType string
+
+const (
+	MediaHDD  DiskMediaType = "HDD"
+	MediaSSD  DiskMediaType = "SSD"
+	MediaNVMe DiskMediaType = "NVME"
+)
+
+// DiskUsage is how a disk is used
+type DiskUsage string
+
+const (
+	DiskUsagePool  DiskUsage = "POOL"
+	DiskUsageSpare DiskUsage = "SPARE"
+	DiskUsageFree  DiskUsage = "FREE"
+	DiskUsageCache DiskUsage = "CACHE"
+)
+
+// return value of GET /rest/v2/storage/disks
+type DiskData struct {
+	ID           string        `json:"id"`
+	EnclosureID  string        `json:"enclosureId"`
+	Slot         int           `json:"slot"`
+	Vendor       string        `json:"vendor"`
+	Model        string        `json:"model"`
+	SerialNumber string        `json:"serialNumber"`
+	FirmwareVer  string        `json:"firmwareVer"`
+	Capacity     uint64        `json:"capacity"`
+	MediaType    DiskMediaType `json:"mediaType"`
+	Usage        DiskUsage     `json:"usage"`
+	PoolID       string        `json:"poolId"`
+	Health       HealthStatus  `json:"health"`
+	State        string        `json:"state"`
+	SmartStatus  string        `json:"smartStatus"`
+}
+
+// return value of GET /rest/v2/storage/disks/_diskID/smart
+type SmartData struct {
+	DiskID       string `json:"diskId"`
+	Status       string `json:"status"`
+	Temperature  int    `json:"temperature"`
+	PowerOnHours uint64 `json:"powerOnHours"`
+	Attributes   []struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		Value     int    `json:"value"`
+		Worst     int    `json:"worst"`
+		Threshold int    `json:"threshold"`
+		Raw       uint64 `json:"raw"`
+	} `json:"attributes"`
+}
+
+// NewDisk returns disk operation
+func NewDisk(client *AuthClient) *DiskOp {
+	return &DiskOp{client}
+}
+
+// ListDisks list all physical disks
+func (v *DiskOp) ListDisks(ctx context.Context) (*[]DiskData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/disks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []DiskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListDiskByID list a dedicated disk with diskId
+func (v *DiskOp) ListDiskByID(ctx context.Context, diskId string) (*DiskData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/disks/"+diskId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := DiskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListDiskBySlot list the disk in certain slot of an enclosure
+func (v *DiskOp) ListDiskBySlot(ctx context.Context, enclosureId string, slot int) (*DiskData, error) {
+	disks, err := v.ListDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range *disks {
+		if d.EnclosureID == enclosureId && d.Slot == slot {
+			disk := d
+			return &disk, nil
+		}
+	}
+	return nil, fmt.Errorf("Disk not found in enclosure %s slot %d.", enclosureId, slot)
+}
+
+// list disks under given PoolID
+func (v *DiskOp) ListDisksByPoolID(ctx context.Context, poolId string) (*[]DiskData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/disks?q=poolId='"+poolId+"'", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []DiskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Get SMART status and attributes of certain disk
+// GET /rest/v2/storage/disks/_diskID/smart
+func (v *DiskOp) GetSmart(ctx context.Context, diskId string) (*SmartData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/disks/"+diskId+"/smart", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := SmartData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
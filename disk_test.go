@@ -0,0 +1,105 @@
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestDisk(t *testing.T) {
+	fmt.Println("------------TestDisk--------------")
+	ctx = context.Background()
+
+	listDiskTest(t)
+	spareTest(t)
+}
+
+func listDiskTest(t *testing.T) {
+	fmt.Println("listDiskTest Enter")
+
+	disks, err := testConf.diskOp.ListDisks(ctx)
+	if err != nil {
+		t.Fatalf("ListDisks failed: %v", err)
+	}
+	fmt.Printf("  ListDisks cnt: %d\n", len(*disks))
+
+	if len(*disks) >= 1 {
+		first := (*disks)[0]
+		disk, err := testConf.diskOp.ListDiskBySlot(ctx, first.EnclosureID, first.Slot)
+		if err != nil {
+			t.Fatalf("ListDiskBySlot with first exist slot(%s, %d) failed: %v", first.EnclosureID, first.Slot, err)
+		}
+		if disk.ID != first.ID {
+			t.Fatalf("ListDiskBySlot returned disk %s, expected %s", disk.ID, first.ID)
+		}
+		fmt.Printf("  ListDiskBySlot(%s, %d)\n  %+v\n", first.EnclosureID, first.Slot, disk)
+
+		smart, err := testConf.diskOp.GetSmart(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("GetSmart of disk %s failed: %v", first.ID, err)
+		}
+		fmt.Printf("  GetSmart(%s): %+v\n", first.ID, smart)
+	}
+
+	disks, err = testConf.diskOp.ListDisksByPoolID(ctx, testConf.poolId)
+	if err != nil {
+		t.Fatalf("ListDisksByPoolID failed: %v", err)
+	}
+	fmt.Printf("  ListDisksByPoolID cnt: %d\n", len(*disks))
+
+	fmt.Println("listDiskTest Leave")
+}
+
+func spareTest(t *testing.T) {
+	fmt.Println("spareTest Enter")
+
+	spares, err := testConf.diskOp.ListSpares(ctx)
+	if err != nil {
+		t.Fatalf("ListSpares failed: %v", err)
+	}
+	fmt.Printf("  ListSpares cnt: %d\n", len(*spares))
+
+	if _, err := testConf.diskOp.AssignSpare(ctx, &SpareData{DiskID: "0", Type: SpareLocal}); err == nil {
+		t.Fatalf("AssignSpare of local spare without pool should fail")
+	}
+
+	coverage, err := testConf.diskOp.SpareCoverage(ctx)
+	if err != nil {
+		t.Fatalf("SpareCoverage failed: %v", err)
+	}
+	for _, c := range coverage {
+		fmt.Printf("  Pool %s %s: covered %t by %v\n", c.PoolID, c.MediaType, c.Covered, c.Spares)
+	}
+
+	fmt.Println("spareTest Leave")
+}
+
+func TestSpareCoverage(t *testing.T) {
+	disks := []DiskData{
+		{ID: "1", MediaType: MediaHDD, Capacity: 4000, Usage: DiskUsagePool, PoolID: "P1"},
+		{ID: "2", MediaType: MediaHDD, Capacity: 8000, Usage: DiskUsagePool, PoolID: "P1"},
+		{ID: "3", MediaType: MediaSSD, Capacity: 960, Usage: DiskUsagePool, PoolID: "P1"},
+		{ID: "4", MediaType: MediaHDD, Capacity: 4000, Usage: DiskUsagePool, PoolID: "P2"},
+		{ID: "5", MediaType: MediaHDD, Capacity: 4000, Usage: DiskUsageSpare},
+		{ID: "6", MediaType: MediaHDD, Capacity: 8000, Usage: DiskUsageSpare},
+		{ID: "7", MediaType: MediaSSD, Capacity: 960, Usage: DiskUsageSpare},
+	}
+	spares := []SpareData{
+		{DiskID: "5", Type: SpareGlobal},
+		{DiskID: "6", Type: SpareLocal, PoolID: "P2"},
+		{DiskID: "7", Type: SpareDedicated, PoolID: "P1", DiskGroupID: "1"},
+	}
+
+	want := []string{
+		"P1 HDD false []",
+		"P1 SSD false []",
+		"P2 HDD true [5 6]",
+	}
+	got := []string{}
+	for _, c := range spareCoverage(disks, spares) {
+		got = append(got, fmt.Sprintf("%s %s %t %v", c.PoolID, c.MediaType, c.Covered, c.Spares))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Default polling interval of Tail
+const defaultTailInterval = 5 * time.Second
+
+// EventOp handles event log related methods of the QSAN storage.
+type EventOp struct {
+	client *AuthClient
+}
+
+// EventSeverity is the severity of an event
+type EventSeverity string
+
+const (
+	EventInfo     EventSeverity = "INFO"
+	EventWarning  EventSeverity = "WARNING"
+	EventError    EventSeverity = "ERROR"
+	EventCritical EventSeverity = "CRITICAL"
+)
+
+// return value of GET /rest/v2/system/events
+type Event struct {
+	ID       uint64        `json:"id"`
+	Time     int64         `json:"time"`
+	Severity EventSeverity `json:"severity"`
+	Category string        `json:"category"`
+	Code     int           `json:"code"`
+	Source   string        `json:"source"`
+	Message  string        `json:"message"`
+}
+
+// EventFilter selects events of ListEvents and Tail. Zero values are not filtered.
+type EventFilter struct {
+	Severities []EventSeverity
+	Category   string
+	Keyword    string
+	From       time.Time
+	To         time.Time
+	// Only events with ID greater than AfterID are returned
+	AfterID uint64
+	Limit   int
+}
+
+func (f *EventFilter) query() string {
+	if f == nil {
+		return ""
+	}
+
+	params := url.Values{}
+	if len(f.Severities) > 0 {
+		sevs := make([]string, len(f.Severities))
+		for i, s := range f.Severities {
+			sevs[i] = string(s)
+		}
+		params.Add("severity", strings.Join(sevs, ","))
+	}
+	if f.Category != "" {
+		params.Add("category", f.Category)
+	}
+	if f.Keyword != "" {
+		params.Add("keyword", f.Keyword)
+	}
+	if !f.From.IsZero() {
+		params.Add("from", strconv.FormatInt(f.From.Unix(), 10))
+	}
+	if !f.To.IsZero() {
+		params.Add("to", strconv.FormatInt(f.To.Unix(), 10))
+	}
+	if f.AfterID != 0 {
+		params.Add("afterId", strconv.FormatUint(f.AfterID, 10))
+	}
+	if f.Limit > 0 {
+		params.Add("limit", strconv.Itoa(f.Limit))
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
+
+// NewEvent returns event operation
+func NewEvent(client *AuthClient) *EventOp {
+	return &EventOp{client}
+}
+
+// ListEvents list system events matching the filter. filter can be nil.
+func (v *EventOp) ListEvents(ctx context.Context, filter *EventFilter) (*[]Event, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/events"+filter.query(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Event{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Tail polls new events matching the filter every interval, and delivers them in order of ID.
+// If filter.AfterID is zero, only events after the call are delivered.
+// Polling errors are sent to the error channel without blocking and polling goes on.
+// Both channels are closed when ctx is done.
+func (v *EventOp) Tail(ctx context.Context, filter *EventFilter, interval time.Duration) (<-chan Event, <-chan error) {
+	if interval <= 0 {
+		interval = defaultTailInterval
+	}
+
+	f := EventFilter{}
+	if filter != nil {
+		f = *filter
+	}
+
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		// Cursor starts from the latest event
+		initialized := f.AfterID != 0
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			res, err := v.ListEvents(ctx, &f)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				glog.Warningf("[Tail] ListEvents failed: %v\n", err)
+				select {
+				case errs <- err:
+				default:
+				}
+			} else {
+				sort.Slice(*res, func(i, j int) bool { return (*res)[i].ID < (*res)[j].ID })
+				for _, e := range *res {
+					if e.ID <= f.AfterID {
+						continue
+					}
+					f.AfterID = e.ID
+					if !initialized {
+						continue
+					}
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if f.Limit > 0 && len(*res) >= f.Limit {
+					// More events are pending, fetch the next page now
+					continue
+				}
+				initialized = true
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
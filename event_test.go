@@ -0,0 +1,61 @@
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	fmt.Println("------------TestEvent--------------")
+	ctx = context.Background()
+
+	listEventTest(t)
+	tailEventTest(t)
+}
+
+func listEventTest(t *testing.T) {
+	fmt.Println("listEventTest Enter")
+
+	events, err := testConf.eventOp.ListEvents(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListEvents failed: %v", err)
+	}
+	fmt.Printf("  ListEvents cnt: %d\n", len(*events))
+
+	filter := &EventFilter{
+		Severities: []EventSeverity{EventWarning, EventError, EventCritical},
+		From:       time.Now().Add(-24 * time.Hour),
+		Limit:      10,
+	}
+	events, err = testConf.eventOp.ListEvents(ctx, filter)
+	if err != nil {
+		t.Fatalf("ListEvents with filter failed: %v", err)
+	}
+	fmt.Printf("  ListEvents with filter %+v cnt: %d\n", filter, len(*events))
+	for _, e := range *events {
+		if e.Severity == EventInfo {
+			t.Fatalf("ListEvents with filter returned INFO event: %+v", e)
+		}
+	}
+
+	fmt.Println("listEventTest Leave")
+}
+
+func tailEventTest(t *testing.T) {
+	fmt.Println("tailEventTest Enter")
+
+	tailCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	events, errs := testConf.eventOp.Tail(tailCtx, nil, 2*time.Second)
+	for e := range events {
+		fmt.Printf("  Tail event: %+v\n", e)
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("Tail failed: %v", err)
+	}
+
+	fmt.Println("tailEventTest Leave")
+}
@@ -0,0 +1,371 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Default polling interval of firmware upgrade
+const defaultUpgradePollInterval = 10 * time.Second
+
+// Default timeout of Upgrade and WaitForVersion if the context has no deadline
+const defaultUpgradeTimeout = 60 * time.Minute
+
+// FirmwareOp handles firmware upgrade related methods of the QSAN storage.
+type FirmwareOp struct {
+	client *AuthClient
+}
+
+// UpgradeState is the state of firmware upgrade
+type UpgradeState string
+
+const (
+	UpgradeIdle       UpgradeState = "IDLE"
+	UpgradeUploaded   UpgradeState = "UPLOADED"
+	UpgradeValidating UpgradeState = "VALIDATING"
+	UpgradeValid      UpgradeState = "VALID"
+	UpgradeInvalid    UpgradeState = "INVALID"
+	UpgradeUpgrading  UpgradeState = "UPGRADING"
+	UpgradeRestarting UpgradeState = "RESTARTING"
+	UpgradeDone       UpgradeState = "DONE"
+	UpgradeFailed     UpgradeState = "FAILED"
+)
+
+// UpgradeStage is the stage reported by UpgradeOptions.Progress
+type UpgradeStage string
+
+const (
+	StageUpload   UpgradeStage = "upload"
+	StageValidate UpgradeStage = "validate"
+	StageUpgrade  UpgradeStage = "upgrade"
+	StageRestart  UpgradeStage = "restart"
+)
+
+// return value of GET /rest/v2/system/firmware
+type FirmwareStatus struct {
+	State        UpgradeState `json:"state"`
+	ImageVersion string       `json:"imageVersion"` // version of the uploaded image
+	Progress     int          `json:"progress"`
+	Message      string       `json:"message"`
+}
+
+// UpgradeOptions are options of Upgrade.
+type UpgradeOptions struct {
+	// Version is the firmware version of the image, e.g. "1.3.0".
+	// The build number is only compared if it is given, e.g. "1.3.0.0123".
+	Version string
+	// Image is only read if it was not uploaded by a previous run
+	Image     io.Reader
+	ImageName string
+	ImageSize int64
+	// Progress reports the stage and its percentage. Optional.
+	Progress     func(stage UpgradeStage, percent int)
+	PollInterval time.Duration
+	// Timeout is only used if ctx has no deadline. Default is 60 minutes.
+	Timeout time.Duration
+}
+
+// NewFirmware returns firmware operation
+func NewFirmware(client *AuthClient) *FirmwareOp {
+	return &FirmwareOp{client}
+}
+
+// GetStatus get the state of firmware upgrade
+func (v *FirmwareOp) GetStatus(ctx context.Context) (*FirmwareStatus, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/firmware", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := FirmwareStatus{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UploadImage streams a firmware image as multipart body. size is the length of r, or -1 if unknown.
+// POST /rest/v2/system/firmware/image
+func (v *FirmwareOp) UploadImage(ctx context.Context, name string, r io.Reader, size int64, progress ProgressFunc) (*FirmwareStatus, error) {
+	// The streaming body cannot be sent again, so make sure the access token is fresh first.
+	if _, err := v.GetStatus(ctx); err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", name)
+		if err == nil {
+			_, err = io.Copy(part, newProgressReader(r, size, progress))
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/firmware/image", pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	res := FirmwareStatus{}
+	err = v.client.SendRequest(ctx, req, &res)
+	pr.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ValidateImage validates the uploaded image
+// POST /rest/v2/system/firmware/validate
+func (v *FirmwareOp) ValidateImage(ctx context.Context) (*FirmwareStatus, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/firmware/validate", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := FirmwareStatus{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// StartUpgrade starts to upgrade with the validated image
+// POST /rest/v2/system/firmware/upgrade
+func (v *FirmwareOp) StartUpgrade(ctx context.Context) (*FirmwareStatus, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/firmware/upgrade", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := FirmwareStatus{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Upgrade uploads, validates and installs the firmware, then waits until every controller runs the new version.
+// It is safe to call again after an interruption, finished steps are skipped according to GetStatus.
+// If the storage already reports the version, it only waits for every controller.
+func (v *FirmwareOp) Upgrade(ctx context.Context, opts *UpgradeOptions) error {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultUpgradePollInterval
+	}
+	ctx, cancel := withDefaultTimeout(ctx, opts.Timeout)
+	defer cancel()
+	report := func(stage UpgradeStage, percent int) {
+		if opts.Progress != nil {
+			opts.Progress(stage, percent)
+		}
+	}
+
+	system := NewSystem(&v.client.Client)
+	about, err := system.GetAbout(ctx)
+	if err != nil {
+		return err
+	}
+	addresses := []string{}
+	for _, a := range about.Addresses {
+		addresses = append(addresses, a.Address)
+	}
+
+	// The other controller may still be restarting, so only the install steps are skipped
+	if sameFirmware(about.FirmwareVer, opts.Version) {
+		glog.V(2).Infof("[Upgrade] firmware is already %s, waiting for all controllers\n", opts.Version)
+	} else if err := v.install(ctx, opts, interval, report); err != nil {
+		return err
+	}
+
+	report(StageRestart, 0)
+	if err := v.WaitForVersion(ctx, addresses, opts.Version, interval); err != nil {
+		return err
+	}
+	report(StageRestart, 100)
+
+	return nil
+}
+
+// Upload, validate and start the upgrade, then wait until the controllers restart
+func (v *FirmwareOp) install(ctx context.Context, opts *UpgradeOptions, interval time.Duration, report func(UpgradeStage, int)) error {
+	status, err := v.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	glog.V(2).Infof("[Upgrade] current state: %+v\n", status)
+
+	sameImage := sameFirmware(status.ImageVersion, opts.Version)
+	switch {
+	case status.State == UpgradeUpgrading || status.State == UpgradeRestarting || (sameImage && status.State == UpgradeDone):
+		// Resume waiting
+	case sameImage && (status.State == UpgradeValid || status.State == UpgradeValidating || status.State == UpgradeUploaded):
+		if err := v.validateAndStart(ctx, status, interval, report); err != nil {
+			return err
+		}
+	default:
+		if opts.Image == nil {
+			return fmt.Errorf("firmware image %s is not uploaded and no image is given", opts.Version)
+		}
+		status, err = v.UploadImage(ctx, opts.ImageName, opts.Image, opts.ImageSize, func(n, total int64) {
+			if total > 0 {
+				report(StageUpload, int(n*100/total))
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("upload firmware image failed: %v", err)
+		}
+		report(StageUpload, 100)
+		if err := v.validateAndStart(ctx, status, interval, report); err != nil {
+			return err
+		}
+	}
+
+	// Wait for the upgrade. The storage stops responding while controllers restart.
+	for {
+		status, err := v.GetStatus(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			glog.V(2).Infof("[Upgrade] GetStatus failed, controllers may be restarting: %v\n", err)
+			break
+		}
+		if status.State == UpgradeFailed {
+			return fmt.Errorf("firmware upgrade failed: %s", status.Message)
+		}
+		if status.State == UpgradeRestarting || status.State == UpgradeDone {
+			break
+		}
+		report(StageUpgrade, status.Progress)
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+	report(StageUpgrade, 100)
+
+	return nil
+}
+
+func (v *FirmwareOp) validateAndStart(ctx context.Context, status *FirmwareStatus, interval time.Duration, report func(UpgradeStage, int)) error {
+	var err error
+	if status.State == UpgradeUploaded {
+		if status, err = v.ValidateImage(ctx); err != nil {
+			return fmt.Errorf("validate firmware image failed: %v", err)
+		}
+	}
+
+	for status.State == UpgradeValidating {
+		report(StageValidate, status.Progress)
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+		if status, err = v.GetStatus(ctx); err != nil {
+			return err
+		}
+	}
+	if status.State != UpgradeValid {
+		return fmt.Errorf("firmware image is %s: %s", status.State, status.Message)
+	}
+	report(StageValidate, 100)
+
+	if _, err := v.StartUpgrade(ctx); err != nil {
+		return fmt.Errorf("start firmware upgrade failed: %v", err)
+	}
+
+	return nil
+}
+
+// WaitForVersion polls GetAbout on every address until all of them are online with the firmware version.
+// If ctx has no deadline, it gives up after 60 minutes.
+func (v *FirmwareOp) WaitForVersion(ctx context.Context, addresses []string, version string, interval time.Duration) error {
+	if len(addresses) == 0 {
+		return errors.New("no controller address to wait for")
+	}
+	if interval <= 0 {
+		interval = defaultUpgradePollInterval
+	}
+	ctx, cancel := withDefaultTimeout(ctx, 0)
+	defer cancel()
+
+	base, err := url.Parse(v.client.baseURL)
+	if err != nil {
+		return err
+	}
+
+	pending := map[string]bool{}
+	for _, addr := range addresses {
+		pending[addr] = true
+	}
+
+	for {
+		for addr := range pending {
+			u := *base
+			u.Host = addr
+			if base.Port() != "" {
+				u.Host = fmt.Sprintf("%s:%s", addr, base.Port())
+			}
+			system := NewSystem(&Client{baseURL: u.String(), HTTPClient: v.client.HTTPClient})
+
+			about, err := system.GetAbout(ctx)
+			if err != nil {
+				glog.V(3).Infof("[WaitForVersion] %s is not ready: %v\n", addr, err)
+				continue
+			}
+			if sameFirmware(about.FirmwareVer, version) {
+				glog.V(2).Infof("[WaitForVersion] %s is running %s\n", addr, about.FirmwareVer)
+				delete(pending, addr)
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			remaining := []string{}
+			for addr := range pending {
+				remaining = append(remaining, addr)
+			}
+			return fmt.Errorf("controllers %v are not running %s: %w", remaining, version, err)
+		}
+	}
+}
+
+// Add the timeout, or defaultUpgradeTimeout if it is 0, to ctx without a deadline
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	if timeout <= 0 {
+		timeout = defaultUpgradeTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func sameFirmware(a, b string) bool {
+	va, errA := ParseFirmwareVersion(a)
+	vb, errB := ParseFirmwareVersion(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return va.Matches(vb)
+}
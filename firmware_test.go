@@ -0,0 +1,63 @@
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFirmware(t *testing.T) {
+	fmt.Println("------------TestFirmware--------------")
+	ctx = context.Background()
+
+	getFirmwareStatusTest(t)
+}
+
+func getFirmwareStatusTest(t *testing.T) {
+	fmt.Println("getFirmwareStatusTest Enter")
+
+	status, err := testConf.firmwareOp.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	fmt.Printf("  Firmware status: %+v\n", status)
+
+	// Upgrade to the running version must return without uploading anything
+	about, err := testConf.systemOp.GetAbout(ctx)
+	if err != nil {
+		t.Fatalf("getAbout failed: %v", err)
+	}
+	// An unreachable controller address must not hang the test
+	upgradeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	if err := testConf.firmwareOp.Upgrade(upgradeCtx, &UpgradeOptions{Version: about.FirmwareVer}); err != nil {
+		t.Fatalf("Upgrade to the running version %s failed: %v", about.FirmwareVer, err)
+	}
+
+	fmt.Println("getFirmwareStatusTest Leave")
+}
+
+func TestSameFirmware(t *testing.T) {
+	fmt.Println("------------TestSameFirmware--------------")
+
+	tests := []struct {
+		running, version string
+		same             bool
+	}{
+		{"1.3.0", "1.3.0", true},
+		{"1.3.0.0123", "1.3.0", true},
+		{"1.3.0 (build 0123)", "1.3.0.0123", true},
+		{"v1.3.0.0123", "1.3.0.0123", true},
+		{"1.3.0.0123", "1.3.0.0124", false},
+		{"1.3.1.0123", "1.3.0", false},
+		{"1.3.0-beta", "1.3.0", false},
+		{"1.3", "1.3.0", true},
+		{"unknown", "1.3.0", false},
+	}
+	for _, tt := range tests {
+		if same := sameFirmware(tt.running, tt.version); same != tt.same {
+			t.Fatalf("sameFirmware(%s, %s) = %t, expected %t", tt.running, tt.version, same, tt.same)
+		}
+	}
+}
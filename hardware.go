@@ -0,0 +1,306 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"net/http"
+)
+
+// HealthStatus is the health of a hardware component
+type HealthStatus string
+
+const (
+	HealthGood     HealthStatus = "GOOD"
+	HealthWarning  HealthStatus = "WARNING"
+	HealthDegraded HealthStatus = "DEGRADED"
+	HealthFailed   HealthStatus = "FAILED"
+	HealthAbsent   HealthStatus = "ABSENT"
+	HealthUnknown  HealthStatus = "UNKNOWN"
+)
+
+// Severity orders health status from good to failed.
+// A missing component, e.g. a pulled power supply, is as severe as a warning.
+func (h HealthStatus) Severity() int {
+	switch h {
+	case HealthGood:
+		return 0
+	case HealthWarning, HealthAbsent:
+		return 1
+	case HealthDegraded, HealthUnknown:
+		return 2
+	case HealthFailed:
+		return 3
+	}
+	return 2
+}
+
+// ControllerRole is the role of a controller
+type ControllerRole string
+
+const (
+	ControllerPrimary   ControllerRole = "PRIMARY"
+	ControllerSecondary ControllerRole = "SECONDARY"
+)
+
+// EnclosureType is the type of an enclosure
+type EnclosureType string
+
+const (
+	EnclosureHead      EnclosureType = "HEAD"
+	EnclosureExpansion EnclosureType = "JBOD"
+)
+
+// return value of GET /rest/v2/system/controllers
+type ControllerData struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	EnclosureID  string         `json:"enclosureId"`
+	Status       HealthStatus   `json:"status"`
+	Role         ControllerRole `json:"role"`
+	Uptime       uint64         `json:"uptime"` // seconds
+	FirmwareVer  string         `json:"firmwareVer"`
+	SerialNumber string         `json:"serialNumber"`
+	MemorySize   uint64         `json:"memorySize"`
+}
+
+// return value of GET /rest/v2/system/enclosures
+type EnclosureData struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Type         EnclosureType `json:"type"`
+	Status       HealthStatus  `json:"status"`
+	ModelName    string        `json:"modelName"`
+	SerialNumber string        `json:"serialNumber"`
+	NumOfSlots   int           `json:"numOfSlots"`
+}
+
+// return value of GET /rest/v2/system/fans
+type FanData struct {
+	ID          string       `json:"id"`
+	EnclosureID string       `json:"enclosureId"`
+	Location    string       `json:"location"`
+	Status      HealthStatus `json:"status"`
+	Speed       int          `json:"speed"` // RPM
+}
+
+// return value of GET /rest/v2/system/powerSupplies
+type PowerSupplyData struct {
+	ID          string       `json:"id"`
+	EnclosureID string       `json:"enclosureId"`
+	Location    string       `json:"location"`
+	Status      HealthStatus `json:"status"`
+	Wattage     int          `json:"wattage"`
+}
+
+// return value of GET /rest/v2/system/temperatures
+type TemperatureData struct {
+	ID          string       `json:"id"`
+	EnclosureID string       `json:"enclosureId"`
+	Location    string       `json:"location"`
+	Status      HealthStatus `json:"status"`
+	Celsius     int          `json:"celsius"`
+}
+
+// return value of GET /rest/v2/system/cacheBackupModules
+type CacheBackupModuleData struct {
+	ID           string       `json:"id"`
+	ControllerID string       `json:"controllerId"`
+	Type         string       `json:"type"`
+	Status       HealthStatus `json:"status"`
+	ChargeLevel  int          `json:"chargeLevel"` // percentage
+}
+
+// HealthProblem is a hardware component which is not healthy
+type HealthProblem struct {
+	Component string
+	ID        string
+	Location  string
+	Status    HealthStatus
+}
+
+// HealthSummary is the aggregate health of all hardware components
+type HealthSummary struct {
+	Status     HealthStatus
+	Components int
+	Problems   []HealthProblem
+}
+
+func (h *HealthSummary) add(component, id, location string, status HealthStatus) {
+	h.Components++
+	if status.Severity() > h.Status.Severity() {
+		h.Status = status
+	}
+	if status.Severity() > 0 {
+		h.Problems = append(h.Problems, HealthProblem{Component: component, ID: id, Location: location, Status: status})
+	}
+}
+
+// ListControllers list all controllers
+func (s *SystemOp) ListControllers(ctx context.Context) (*[]ControllerData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/controllers", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []ControllerData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListEnclosures list the head enclosure and all expansion enclosures
+func (s *SystemOp) ListEnclosures(ctx context.Context) (*[]EnclosureData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/enclosures", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []EnclosureData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListFans list all fans
+func (s *SystemOp) ListFans(ctx context.Context) (*[]FanData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/fans", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []FanData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListPowerSupplies list all power supplies
+func (s *SystemOp) ListPowerSupplies(ctx context.Context) (*[]PowerSupplyData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/powerSupplies", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []PowerSupplyData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListTemperatures list all temperature sensors
+func (s *SystemOp) ListTemperatures(ctx context.Context) (*[]TemperatureData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/temperatures", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []TemperatureData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListCacheBackupModules list all cache backup modules
+func (s *SystemOp) ListCacheBackupModules(ctx context.Context) (*[]CacheBackupModuleData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/cacheBackupModules", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []CacheBackupModuleData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// HealthSummary collects all hardware components and returns the worst status
+func (s *SystemOp) HealthSummary(ctx context.Context) (*HealthSummary, error) {
+	sum := &HealthSummary{Status: HealthGood}
+
+	ctrls, err := s.ListControllers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range *ctrls {
+		sum.add("controller", c.ID, c.Name, c.Status)
+	}
+
+	encls, err := s.ListEnclosures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range *encls {
+		sum.add("enclosure", e.ID, e.Name, e.Status)
+	}
+
+	fans, err := s.ListFans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range *fans {
+		sum.add("fan", f.ID, f.Location, f.Status)
+	}
+
+	psus, err := s.ListPowerSupplies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range *psus {
+		sum.add("powerSupply", p.ID, p.Location, p.Status)
+	}
+
+	temps, err := s.ListTemperatures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range *temps {
+		sum.add("temperature", t.ID, t.Location, t.Status)
+	}
+
+	cbms, err := s.ListCacheBackupModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range *cbms {
+		sum.add("cacheBackupModule", c.ID, c.ControllerID, c.Status)
+	}
+
+	return sum, nil
+}
@@ -15,14 +15,23 @@ import (
 )
 
 type testConfig struct {
-	ip       string
-	user     string
-	passwd   string
-	poolId   string
-	systemOp *SystemOp
-	poolOp   *PoolOp
-	volumeOp *VolumeOp
-	targetOp *TargetOp
+	ip             string
+	user           string
+	passwd         string
+	poolId         string
+	systemOp       *SystemOp
+	authSysOp      *SystemOp
+	poolOp         *PoolOp
+	volumeOp       *VolumeOp
+	targetOp       *TargetOp
+	diskOp         *DiskOp
+	eventOp        *EventOp
+	statsOp        *StatsOp
+	networkOp      *NetworkOp
+	userOp         *UserOp
+	notificationOp *NotificationOp
+	firmwareOp     *FirmwareOp
+	ssdCacheOp     *SSDCacheOp
 }
 
 var testConf *testConfig
@@ -59,11 +68,23 @@ func TestMain(m *testing.M) {
 	}
 
 	testConf.systemOp = NewSystem(testClient)
+	testConf.authSysOp = NewSystemWithAuth(testAuthClient)
 	testConf.poolOp = NewPool(testAuthClient)
 	testConf.volumeOp = NewVolume(testAuthClient)
 	testConf.targetOp = NewTarget(testAuthClient)
+	testConf.diskOp = NewDisk(testAuthClient)
+	testConf.eventOp = NewEvent(testAuthClient)
+	testConf.statsOp = NewStats(testAuthClient)
+	testConf.networkOp = NewNetwork(testAuthClient)
+	testConf.userOp = NewUser(testAuthClient)
+	testConf.notificationOp = NewNotification(testAuthClient)
+	testConf.firmwareOp = NewFirmware(testAuthClient)
+	testConf.ssdCacheOp = NewSSDCache(testAuthClient)
 
 	code := m.Run()
+	if err := testAuthClient.Close(); err != nil {
+		fmt.Printf("Logout failed: %v \n", err)
+	}
 	fmt.Println("------------End of TestMain--------------")
 	os.Exit(code)
 }
@@ -0,0 +1,188 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// NetworkOp handles network interface related methods of the QSAN storage.
+type NetworkOp struct {
+	client *AuthClient
+}
+
+// LinkStatus is the link status of a network port
+type LinkStatus string
+
+const (
+	LinkUp   LinkStatus = "UP"
+	LinkDown LinkStatus = "DOWN"
+)
+
+// Bonding modes of TrunkParam
+const (
+	BondingLACP        = "LACP"
+	BondingFailover    = "FAILOVER"
+	BondingLoadBalance = "LOAD_BALANCE"
+)
+
+// return value of GET /rest/v2/system/network/data
+// return value of GET /rest/v2/system/network/management
+type NetworkPortData struct {
+	Name         string     `json:"name"` // e.g. c0e5
+	ControllerID string     `json:"controllerId"`
+	MacAddress   string     `json:"macAddress"`
+	LinkStatus   LinkStatus `json:"linkStatus"`
+	Speed        int        `json:"speed"` // Mbps
+	Mtu          int        `json:"mtu"`
+	Dhcp         bool       `json:"dhcp"`
+	IP           string     `json:"ip"`
+	Netmask      string     `json:"netmask"`
+	Gateway      string     `json:"gateway"`
+	VlanID       int        `json:"vlanId"`
+	Bonding      struct {
+		Mode    string   `json:"mode"`
+		Members []string `json:"members"`
+	} `json:"bonding"`
+}
+
+// PATCH /rest/v2/system/network/data/_portName
+// PATCH /rest/v2/system/network/management/_portName
+type NetworkPortParam struct {
+	Dhcp    *bool  `json:"dhcp,omitempty"`
+	IP      string `json:"ip,omitempty"`
+	Netmask string `json:"netmask,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+	Mtu     int    `json:"mtu,omitempty"`
+	VlanID  *int   `json:"vlanId,omitempty"` // 0 to disable VLAN
+}
+
+// POST /rest/v2/system/network/data/trunks
+type TrunkParam struct {
+	Name    string   `json:"name,omitempty"`
+	Mode    string   `json:"mode"`
+	Members []string `json:"members"`
+}
+
+// NewNetwork returns network operation
+func NewNetwork(client *AuthClient) *NetworkOp {
+	return &NetworkOp{client}
+}
+
+// ListDataPorts list all data ports
+func (v *NetworkOp) ListDataPorts(ctx context.Context) (*[]NetworkPortData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/network/data", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []NetworkPortData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListManagementPorts list all management ports
+func (v *NetworkOp) ListManagementPorts(ctx context.Context) (*[]NetworkPortData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/network/management", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []NetworkPortData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Patch certain data port
+func (v *NetworkOp) ModifyDataPort(ctx context.Context, portName string, param *NetworkPortParam) (*NetworkPortData, error) {
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/network/data/"+portName, string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := NetworkPortData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Patch certain management port
+func (v *NetworkOp) ModifyManagementPort(ctx context.Context, portName string, param *NetworkPortParam) (*NetworkPortData, error) {
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/network/management/"+portName, string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := NetworkPortData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CreateTrunk bonds data ports into a trunk
+func (v *NetworkOp) CreateTrunk(ctx context.Context, param *TrunkParam) (*NetworkPortData, error) {
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/network/data/trunks", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := NetworkPortData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteTrunk unbonds a trunk
+func (v *NetworkOp) DeleteTrunk(ctx context.Context, trunkName string) error {
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, "/rest/v2/system/network/data/trunks/"+trunkName, nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateEths checks that all given data ports exist and their links are up
+func (v *NetworkOp) ValidateEths(ctx context.Context, eths []string) error {
+	ports, err := v.ListDataPorts(ctx)
+	if err != nil {
+		return err
+	}
+
+	portMap := map[string]NetworkPortData{}
+	for _, p := range *ports {
+		portMap[p.Name] = p
+	}
+
+	for _, eth := range eths {
+		p, ok := portMap[eth]
+		if !ok {
+			return fmt.Errorf("data port %s not found", eth)
+		}
+		if p.LinkStatus != LinkUp {
+			return fmt.Errorf("data port %s link is %s", eth, p.LinkStatus)
+		}
+	}
+
+	return nil
+}
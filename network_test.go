@@ -0,0 +1,46 @@
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestNetwork(t *testing.T) {
+	fmt.Println("------------TestNetwork--------------")
+	ctx = context.Background()
+
+	listNetworkTest(t)
+}
+
+func listNetworkTest(t *testing.T) {
+	fmt.Println("listNetworkTest Enter")
+
+	ports, err := testConf.networkOp.ListDataPorts(ctx)
+	if err != nil {
+		t.Fatalf("ListDataPorts failed: %v", err)
+	}
+	fmt.Printf("  ListDataPorts cnt: %d\n  %+v\n", len(*ports), ports)
+
+	mgmtPorts, err := testConf.networkOp.ListManagementPorts(ctx)
+	if err != nil {
+		t.Fatalf("ListManagementPorts failed: %v", err)
+	}
+	fmt.Printf("  ListManagementPorts cnt: %d\n  %+v\n", len(*mgmtPorts), mgmtPorts)
+
+	for _, p := range *ports {
+		err := testConf.networkOp.ValidateEths(ctx, []string{p.Name})
+		if p.LinkStatus == LinkUp && err != nil {
+			t.Fatalf("ValidateEths(%s) of link up port failed: %v", p.Name, err)
+		}
+		if p.LinkStatus != LinkUp && err == nil {
+			t.Fatalf("ValidateEths(%s) of link down port should fail", p.Name)
+		}
+	}
+
+	if err := testConf.networkOp.ValidateEths(ctx, []string{"nonexistent"}); err == nil {
+		t.Fatalf("ValidateEths with non-existent eth should fail")
+	}
+
+	fmt.Println("listNetworkTest Leave")
+}
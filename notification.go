@@ -0,0 +1,257 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+)
+
+// NotificationOp handles alert notification related methods of the QSAN storage.
+type NotificationOp struct {
+	client *AuthClient
+}
+
+// NotificationChannel is an outbound alert channel
+type NotificationChannel string
+
+const (
+	ChannelSNMP   NotificationChannel = "snmp"
+	ChannelEmail  NotificationChannel = "email"
+	ChannelSyslog NotificationChannel = "syslog"
+)
+
+// PUT /rest/v2/system/notifications/snmp
+type SNMPTrap struct {
+	Host      string        `json:"host"`
+	Port      int           `json:"port"`
+	Community string        `json:"community"`
+	Version   string        `json:"version"`
+	Severity  EventSeverity `json:"severity"` // minimum severity
+}
+
+// GET /rest/v2/system/notifications/snmp
+// PUT /rest/v2/system/notifications/snmp
+type SNMPData struct {
+	Enable      bool       `json:"enable"`
+	Communities []string   `json:"communities"`
+	Traps       []SNMPTrap `json:"traps"`
+}
+
+// PUT /rest/v2/system/notifications/email
+type EmailRecipient struct {
+	Address  string        `json:"address"`
+	Severity EventSeverity `json:"severity"` // minimum severity
+}
+
+// GET /rest/v2/system/notifications/email
+// PUT /rest/v2/system/notifications/email
+type EmailData struct {
+	Enable     bool             `json:"enable"`
+	SmtpServer string           `json:"smtpServer"`
+	SmtpPort   int              `json:"smtpPort"`
+	Sender     string           `json:"sender"`
+	User       string           `json:"user"`
+	Password   string           `json:"password,omitempty"` // never returned by GET
+	UseTLS     bool             `json:"useTls"`
+	Recipients []EmailRecipient `json:"recipients"`
+}
+
+// PUT /rest/v2/system/notifications/syslog
+type SyslogServer struct {
+	Host     string        `json:"host"`
+	Port     int           `json:"port"`
+	Protocol string        `json:"protocol"` // UDP or TCP
+	Facility string        `json:"facility"`
+	Severity EventSeverity `json:"severity"` // minimum severity
+}
+
+// GET /rest/v2/system/notifications/syslog
+// PUT /rest/v2/system/notifications/syslog
+type SyslogData struct {
+	Enable  bool           `json:"enable"`
+	Servers []SyslogServer `json:"servers"`
+}
+
+// NotificationConfig is the whole alert configuration applied by Apply.
+// Nil sections are left unchanged.
+type NotificationConfig struct {
+	SNMP   *SNMPData
+	Email  *EmailData
+	Syslog *SyslogData
+}
+
+// NewNotification returns notification operation
+func NewNotification(client *AuthClient) *NotificationOp {
+	return &NotificationOp{client}
+}
+
+func (v *NotificationOp) get(ctx context.Context, channel NotificationChannel, res interface{}) error {
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/notifications/"+string(channel), nil)
+	if err != nil {
+		return err
+	}
+
+	return v.client.SendRequest(ctx, req, res)
+}
+
+func (v *NotificationOp) set(ctx context.Context, channel NotificationChannel, param, res interface{}) error {
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPut, "/rest/v2/system/notifications/"+string(channel), string(rawdata))
+	if err != nil {
+		return err
+	}
+
+	return v.client.SendRequest(ctx, req, res)
+}
+
+// GetSNMP get SNMP trap settings
+func (v *NotificationOp) GetSNMP(ctx context.Context) (*SNMPData, error) {
+	res := SNMPData{}
+	if err := v.get(ctx, ChannelSNMP, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetSNMP replace SNMP trap settings
+func (v *NotificationOp) SetSNMP(ctx context.Context, param *SNMPData) (*SNMPData, error) {
+	res := SNMPData{}
+	if err := v.set(ctx, ChannelSNMP, param, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetEmail get email settings
+func (v *NotificationOp) GetEmail(ctx context.Context) (*EmailData, error) {
+	res := EmailData{}
+	if err := v.get(ctx, ChannelEmail, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetEmail replace email settings
+func (v *NotificationOp) SetEmail(ctx context.Context, param *EmailData) (*EmailData, error) {
+	res := EmailData{}
+	if err := v.set(ctx, ChannelEmail, param, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetSyslog get remote syslog settings
+func (v *NotificationOp) GetSyslog(ctx context.Context) (*SyslogData, error) {
+	res := SyslogData{}
+	if err := v.get(ctx, ChannelSyslog, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetSyslog replace remote syslog settings
+func (v *NotificationOp) SetSyslog(ctx context.Context, param *SyslogData) (*SyslogData, error) {
+	res := SyslogData{}
+	if err := v.set(ctx, ChannelSyslog, param, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SendTestAlert sends a test alert through the channel
+// POST /rest/v2/system/notifications/_channel/test
+func (v *NotificationOp) SendTestAlert(ctx context.Context, channel NotificationChannel) error {
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/notifications/"+string(channel)+"/test", nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Apply sets every non-nil section of config which differs from the storage,
+// and returns the changed channels. Applying the same config again changes nothing.
+// The email password cannot be compared, so a password change alone is not detected.
+func (v *NotificationOp) Apply(ctx context.Context, config *NotificationConfig) ([]NotificationChannel, error) {
+	changed := []NotificationChannel{}
+
+	if config.SNMP != nil {
+		cur, err := v.GetSNMP(ctx)
+		if err != nil {
+			return changed, err
+		}
+		if !sameNotification(cur, config.SNMP) {
+			if _, err := v.SetSNMP(ctx, config.SNMP); err != nil {
+				return changed, err
+			}
+			changed = append(changed, ChannelSNMP)
+		}
+	}
+
+	if config.Email != nil {
+		cur, err := v.GetEmail(ctx)
+		if err != nil {
+			return changed, err
+		}
+		want := *config.Email
+		want.Password = ""
+		if !sameNotification(cur, &want) {
+			if _, err := v.SetEmail(ctx, config.Email); err != nil {
+				return changed, err
+			}
+			changed = append(changed, ChannelEmail)
+		}
+	}
+
+	if config.Syslog != nil {
+		cur, err := v.GetSyslog(ctx)
+		if err != nil {
+			return changed, err
+		}
+		if !sameNotification(cur, config.Syslog) {
+			if _, err := v.SetSyslog(ctx, config.Syslog); err != nil {
+				return changed, err
+			}
+			changed = append(changed, ChannelSyslog)
+		}
+	}
+
+	return changed, nil
+}
+
+// Compare settings by their JSON form, so that nil and empty lists are the same
+func sameNotification(a, b interface{}) bool {
+	var ja, jb interface{}
+	ra, _ := json.Marshal(a)
+	rb, _ := json.Marshal(b)
+	json.Unmarshal(ra, &ja)
+	json.Unmarshal(rb, &jb)
+
+	return reflect.DeepEqual(normalizeJSON(ja), normalizeJSON(jb))
+}
+
+func normalizeJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeJSON(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeJSON(e)
+		}
+		return v
+	case nil:
+		return []interface{}{}
+	}
+	return v
+}
@@ -0,0 +1,43 @@
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestNotification(t *testing.T) {
+	fmt.Println("------------TestNotification--------------")
+	ctx = context.Background()
+
+	applyNotificationTest(t)
+}
+
+func applyNotificationTest(t *testing.T) {
+	fmt.Println("applyNotificationTest Enter")
+
+	snmp, err := testConf.notificationOp.GetSNMP(ctx)
+	if err != nil {
+		t.Fatalf("GetSNMP failed: %v", err)
+	}
+	email, err := testConf.notificationOp.GetEmail(ctx)
+	if err != nil {
+		t.Fatalf("GetEmail failed: %v", err)
+	}
+	syslog, err := testConf.notificationOp.GetSyslog(ctx)
+	if err != nil {
+		t.Fatalf("GetSyslog failed: %v", err)
+	}
+	fmt.Printf("  SNMP: %+v\n  Email: %+v\n  Syslog: %+v\n", snmp, email, syslog)
+
+	// Applying the current settings must not change anything
+	changed, err := testConf.notificationOp.Apply(ctx, &NotificationConfig{SNMP: snmp, Email: email, Syslog: syslog})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("Apply current settings changed %v", changed)
+	}
+
+	fmt.Println("applyNotificationTest Leave")
+}
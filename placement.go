@@ -0,0 +1,175 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNoPoolAvailable is returned by SelectPool if no pool satisfies the constraints
+var ErrNoPoolAvailable = errors.New("no pool available")
+
+// PlacementConstraints are the requirements of a pool to place a volume.
+type PlacementConstraints struct {
+	// Size is in the unit of PoolData.TotalSize and VolumeData.TotalSize
+	Size      uint64
+	Provision string
+	// RaidLevels are the acceptable RAID levels. Empty means any.
+	RaidLevels []string
+	// AutoTiering requires the pool to have (or not have) auto-tiering if not nil
+	AutoTiering *bool
+	// MinFreePercent is the minimum percentage of free capacity left after placing the volume
+	MinFreePercent float64
+	// IncludePoolIDs limits the candidates if not empty
+	IncludePoolIDs []string
+	ExcludePoolIDs []string
+}
+
+// PoolScorer scores an eligible pool for a volume of size, in the unit of PoolData.TotalSize. Higher is better.
+type PoolScorer func(pool *PoolData, size uint64) float64
+
+var (
+	// ScoreMostFree prefers the pool with the highest free ratio after placement
+	ScoreMostFree PoolScorer = func(pool *PoolData, size uint64) float64 {
+		return freePercent(pool, size)
+	}
+	// ScoreLeastVolumes prefers the pool with the fewest volumes
+	ScoreLeastVolumes PoolScorer = func(pool *PoolData, size uint64) float64 {
+		return -float64(pool.NumOfVolumes)
+	}
+	// ScoreSpread prefers the pool with the lowest subscription ratio, which spreads capacity evenly
+	ScoreSpread PoolScorer = func(pool *PoolData, size uint64) float64 {
+		if pool.TotalSize == 0 {
+			return -1
+		}
+		return -float64(pool.SubscribedSize+size) / float64(pool.TotalSize)
+	}
+)
+
+// PoolCandidate is a pool ranked by RankPools
+type PoolCandidate struct {
+	Pool     PoolData
+	Eligible bool
+	Score    float64
+	Reasons  []string
+}
+
+// RankPools ranks all pools for the constraints. Eligible pools come first, from the highest score.
+// Nil constraints accept every pool, and scorer defaults to ScoreMostFree.
+func (v *PoolOp) RankPools(ctx context.Context, c *PlacementConstraints, scorer PoolScorer) ([]PoolCandidate, error) {
+	pools, err := v.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankPools(*pools, c, scorer), nil
+}
+
+// SelectPool returns the best pool for the constraints, or ErrNoPoolAvailable with the reasons.
+// Nil constraints accept every pool.
+func (v *PoolOp) SelectPool(ctx context.Context, c *PlacementConstraints, scorer PoolScorer) (*PoolData, error) {
+	candidates, err := v.RankPools(ctx, c, scorer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 || !candidates[0].Eligible {
+		reasons := []string{}
+		for _, cand := range candidates {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", cand.Pool.ID, strings.Join(cand.Reasons, ", ")))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoPoolAvailable, strings.Join(reasons, "; "))
+	}
+
+	return &candidates[0].Pool, nil
+}
+
+func rankPools(pools []PoolData, c *PlacementConstraints, scorer PoolScorer) []PoolCandidate {
+	if c == nil {
+		c = &PlacementConstraints{}
+	}
+	if scorer == nil {
+		scorer = ScoreMostFree
+	}
+
+	res := []PoolCandidate{}
+	for i := range pools {
+		pool := &pools[i]
+		reasons := placementReasons(pool, c)
+		if reasons == nil {
+			continue
+		}
+
+		cand := PoolCandidate{Pool: *pool, Reasons: reasons}
+		if len(reasons) == 0 {
+			cand.Eligible = true
+			cand.Score = scorer(pool, c.Size)
+			cand.Reasons = []string{fmt.Sprintf("%.1f%% free after placement, %d volumes", freePercent(pool, c.Size), pool.NumOfVolumes)}
+		}
+		res = append(res, cand)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Eligible != res[j].Eligible {
+			return res[i].Eligible
+		}
+		return res[i].Score > res[j].Score
+	})
+	return res
+}
+
+// placementReasons returns why the pool does not satisfy the constraints, or nil if it is not a candidate at all
+func placementReasons(pool *PoolData, c *PlacementConstraints) []string {
+	if len(c.IncludePoolIDs) > 0 && !containsString(c.IncludePoolIDs, pool.ID) {
+		return nil
+	}
+	if containsString(c.ExcludePoolIDs, pool.ID) {
+		return nil
+	}
+
+	reasons := []string{}
+	if reason := pool.fitReason(c.Size, c.Provision); reason != "" {
+		reasons = append(reasons, reason)
+	}
+	if len(c.RaidLevels) > 0 && !containsString(c.RaidLevels, pool.RaidLevel) {
+		reasons = append(reasons, fmt.Sprintf("RAID level is %s", pool.RaidLevel))
+	}
+	if c.AutoTiering != nil && *c.AutoTiering != pool.AutoTiering {
+		reasons = append(reasons, fmt.Sprintf("auto-tiering is %t", pool.AutoTiering))
+	}
+	if c.MinFreePercent > 0 {
+		if free := freePercent(pool, c.Size); free < c.MinFreePercent {
+			reasons = append(reasons, fmt.Sprintf("%.1f%% free after placement, below %.1f%%", free, c.MinFreePercent))
+		}
+	}
+
+	return reasons
+}
+
+// Percentage of free capacity after placing a volume of size. A thin volume does not allocate capacity.
+func freePercent(pool *PoolData, size uint64) float64 {
+	if pool.TotalSize == 0 {
+		return 0
+	}
+	free := pool.FreeSize
+	if !strings.EqualFold(pool.Provision, ProvisionThin) {
+		if size > free {
+			return 0
+		}
+		free -= size
+	}
+	return float64(free) * 100 / float64(pool.TotalSize)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
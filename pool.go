@@ -2,24 +2,117 @@ package goqsan
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Default polling interval of long-running tasks
+const defaultTaskPollInterval = 5 * time.Second
+
+// ErrPoolNotEmpty is returned by DeletePool if the pool still has volumes
+var ErrPoolNotEmpty = errors.New("pool is not empty")
+
+// RAID levels of a pool
+const (
+	RAID0  = "RAID0"
+	RAID1  = "RAID1"
+	RAID5  = "RAID5"
+	RAID6  = "RAID6"
+	RAID10 = "RAID10"
+	RAID50 = "RAID50"
+	RAID60 = "RAID60"
+)
+
+// Provisioning types of a pool
+const (
+	ProvisionThick = "THICK"
+	ProvisionThin  = "THIN"
 )
 
+// Minimum number of disks of a disk group for each RAID level
+var raidMinDisks = map[string]int{
+	RAID0:  1,
+	RAID1:  2,
+	RAID5:  3,
+	RAID6:  4,
+	RAID10: 4,
+	RAID50: 6,
+	RAID60: 8,
+}
+
 // PoolOp handles pool related methods of the QSAN storage.
 type PoolOp struct {
 	client *AuthClient
 }
 
 type PoolData struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	Provision    string `json:"provision"`
-	AutoTiering  bool   `json:"autoTiering"`
-	RaidLevel    string `json:"raidLevel"`
-	NumOfVolumes int    `json:"numOfVolumes"`
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	Provision            string       `json:"provision"`
+	AutoTiering          bool         `json:"autoTiering"`
+	RaidLevel            string       `json:"raidLevel"`
+	NumOfVolumes         int          `json:"numOfVolumes"`
+	Health               HealthStatus `json:"health"`
+	State                string       `json:"state"`
+	TotalSize            uint64       `json:"totalSize"`
+	UsedSize             uint64       `json:"usedSize"`             // written data
+	FreeSize             uint64       `json:"freeSize"`             // not allocated to any volume
+	AllocatedSize        uint64       `json:"allocatedSize"`        // allocated to volumes
+	SubscribedSize       uint64       `json:"subscribedSize"`       // sum of volume sizes
+	SnapshotReservedSize uint64       `json:"snapshotReservedSize"` // reserved for snapshots
+	BgIoPriority         string       `json:"bgIoPriority"`
+}
+
+// DiskGroupParam is a disk group of a pool. Groups given in one request should have the same number of disks.
+// The storage checks new groups of ExpandPool against the existing groups of the pool.
+type DiskGroupParam struct {
+	DiskIDs []string `json:"diskIds"`
+}
+
+// POST /rest/v2/storage/pools
+type PoolCreateParam struct {
+	Name        string           `json:"name"`
+	RaidLevel   string           `json:"raidLevel"`
+	Provision   string           `json:"provision"`            // ProvisionThick or ProvisionThin
+	StripeSize  uint64           `json:"stripeSize,omitempty"` // KB
+	AutoTiering bool             `json:"autoTiering,omitempty"`
+	DiskGroups  []DiskGroupParam `json:"diskGroups"`
 }
 
-// NewVolume returns volume operation
+// TaskState is the state of a long-running task
+type TaskState string
+
+const (
+	TaskQueued  TaskState = "QUEUED"
+	TaskRunning TaskState = "RUNNING"
+	TaskDone    TaskState = "DONE"
+	TaskFailed  TaskState = "FAILED"
+)
+
+// return value of GET /rest/v2/tasks/_taskId
+type TaskData struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	ResourceID string    `json:"resourceId"`
+	State      TaskState `json:"state"`
+	Progress   int       `json:"progress"`
+	Message    string    `json:"message"`
+	StartTime  int64     `json:"startTime"`
+	EndTime    int64     `json:"endTime"`
+}
+
+// Finished returns true if the task is done or failed
+func (t *TaskData) Finished() bool {
+	return t.State == TaskDone || t.State == TaskFailed
+}
+
+// NewPool returns pool operation
 func NewPool(client *AuthClient) *PoolOp {
 	return &PoolOp{client}
 }
@@ -54,6 +147,203 @@ func (v *PoolOp) ListPoolByID(ctx context.Context, poolId string) (*PoolData, er
 	return &res, nil
 }
 
+// CanFit checks whether a volume of size with provision can be created in a pool.
+// size is in the unit of PoolData.TotalSize and VolumeData.TotalSize.
+func (v *PoolOp) CanFit(ctx context.Context, poolId string, size uint64, provision string) (bool, error) {
+	pool, err := v.ListPoolByID(ctx, poolId)
+	if err != nil {
+		return false, err
+	}
+
+	if reason := pool.fitReason(size, provision); reason != "" {
+		glog.V(2).Infof("[CanFit] pool %s: %s\n", poolId, reason)
+		return false, nil
+	}
+	return true, nil
+}
+
+// fitReason returns why a volume cannot be created in the pool, or empty if it fits.
+// A thick volume allocates its whole size, a thin volume only needs to be within the usable capacity.
+func (p *PoolData) fitReason(size uint64, provision string) string {
+	if provision != "" && p.Provision != "" && !strings.EqualFold(provision, p.Provision) {
+		return fmt.Sprintf("provision is %s, not %s", p.Provision, provision)
+	}
+	if p.Health == HealthFailed {
+		return "pool health is FAILED"
+	}
+
+	if strings.EqualFold(p.Provision, ProvisionThin) {
+		usable := uint64(0)
+		if p.TotalSize > p.SnapshotReservedSize {
+			usable = p.TotalSize - p.SnapshotReservedSize
+		}
+		if size > usable {
+			return fmt.Sprintf("size %d exceeds usable capacity %d", size, usable)
+		}
+		if p.FreeSize == 0 {
+			return "no free capacity"
+		}
+		return ""
+	}
+
+	if size > p.FreeSize {
+		return fmt.Sprintf("size %d exceeds free capacity %d", size, p.FreeSize)
+	}
+	return ""
+}
+
+// CreatePool create a pool with disk groups, and returns the task of pool initialization
+func (v *PoolOp) CreatePool(ctx context.Context, param *PoolCreateParam) (*TaskData, error) {
+	if !strings.EqualFold(param.Provision, ProvisionThick) && !strings.EqualFold(param.Provision, ProvisionThin) {
+		return nil, fmt.Errorf("unknown provision %q, expected %s or %s", param.Provision, ProvisionThick, ProvisionThin)
+	}
+	if err := validateDiskGroups(param.RaidLevel, param.DiskGroups); err != nil {
+		return nil, err
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/storage/pools", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := TaskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ExpandPool adds disk groups to a pool, and returns the task of pool expansion
+// POST /rest/v2/storage/pools/_poolId/diskGroups
+func (v *PoolOp) ExpandPool(ctx context.Context, poolId string, groups []DiskGroupParam) (*TaskData, error) {
+	pool, err := v.ListPoolByID(ctx, poolId)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDiskGroups(pool.RaidLevel, groups); err != nil {
+		return nil, err
+	}
+
+	param := struct {
+		DiskGroups []DiskGroupParam `json:"diskGroups"`
+	}{groups}
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/storage/pools/"+poolId+"/diskGroups", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := TaskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeletePool delete a pool. It fails with ErrPoolNotEmpty if the pool has volumes, unless force is true.
+// DELETE /rest/v2/storage/pools/_poolId
+func (v *PoolOp) DeletePool(ctx context.Context, poolId string, force bool) (*TaskData, error) {
+	path := "/rest/v2/storage/pools/" + poolId
+	if force {
+		path += "?force=true"
+	} else {
+		vols, err := NewVolume(v.client).ListVolumesByPoolID(ctx, poolId)
+		if err != nil {
+			return nil, err
+		}
+		if len(*vols) > 0 {
+			return nil, fmt.Errorf("%w: pool %s has %d volumes", ErrPoolNotEmpty, poolId, len(*vols))
+		}
+	}
+
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := TaskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetTask get a long-running task with taskId
+func (v *PoolOp) GetTask(ctx context.Context, taskId string) (*TaskData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/tasks/"+taskId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := TaskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WaitTask polls a task until it is finished. progress is called on every poll if not nil.
+// It returns an error if the task failed.
+func (v *PoolOp) WaitTask(ctx context.Context, taskId string, interval time.Duration, progress func(*TaskData)) (*TaskData, error) {
+	if interval <= 0 {
+		interval = defaultTaskPollInterval
+	}
+
+	for {
+		task, err := v.GetTask(ctx, taskId)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(task)
+		}
+		glog.V(3).Infof("[WaitTask] task %s: %s %d%%\n", taskId, task.State, task.Progress)
+
+		if task.State == TaskFailed {
+			return task, fmt.Errorf("task %s failed: %s", taskId, task.Message)
+		}
+		if task.State == TaskDone {
+			return task, nil
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return task, err
+		}
+	}
+}
+
+func validateDiskGroups(raidLevel string, groups []DiskGroupParam) error {
+	min, ok := raidMinDisks[raidLevel]
+	if !ok {
+		return fmt.Errorf("unknown RAID level %s", raidLevel)
+	}
+	if len(groups) == 0 {
+		return errors.New("no disk group is given")
+	}
+
+	seen := map[string]bool{}
+	for i, g := range groups {
+		if len(g.DiskIDs) < min {
+			return fmt.Errorf("disk group %d has %d disks, %s needs at least %d", i, len(g.DiskIDs), raidLevel, min)
+		}
+		if len(g.DiskIDs) != len(groups[0].DiskIDs) {
+			return fmt.Errorf("disk group %d has %d disks, expected %d as the first group", i, len(g.DiskIDs), len(groups[0].DiskIDs))
+		}
+		if (raidLevel == RAID1 || raidLevel == RAID10) && len(g.DiskIDs)%2 != 0 {
+			return fmt.Errorf("disk group %d of %s needs an even number of disks", i, raidLevel)
+		}
+		for _, id := range g.DiskIDs {
+			if seen[id] {
+				return fmt.Errorf("disk %s is used more than once", id)
+			}
+			seen[id] = true
+		}
+	}
+
+	return nil
+}
+
 // Search pools under given pool name
 // func (v *PoolOp) ListPoolsBySearchPoolName(ctx context.Context, searchPoolName string) (*[]PoolData, error) {
 
@@ -2,8 +2,10 @@ package goqsan
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestPool(t *testing.T) {
@@ -11,6 +13,10 @@ func TestPool(t *testing.T) {
 	ctx = context.Background()
 
 	listPoolTest(t)
+	poolLifecycleTest(t)
+	canFitTest(t)
+	tieringTest(t)
+	poolTaskTest(t)
 }
 
 func listPoolTest(t *testing.T) {
@@ -32,3 +38,199 @@ func listPoolTest(t *testing.T) {
 
 	fmt.Println("listPoolTest Leave")
 }
+
+func poolLifecycleTest(t *testing.T) {
+	fmt.Println("poolLifecycleTest Enter")
+
+	// Invalid layouts are refused before sending any request
+	_, err := testConf.poolOp.CreatePool(ctx, &PoolCreateParam{
+		Name:       "goqsan-invalid",
+		RaidLevel:  RAID6,
+		Provision:  ProvisionThick,
+		DiskGroups: []DiskGroupParam{{DiskIDs: []string{"1", "2", "3"}}},
+	})
+	if err == nil {
+		t.Fatalf("CreatePool with 3 disks of RAID6 should fail")
+	}
+
+	pools, err := testConf.poolOp.ListPools(ctx)
+	if err != nil {
+		t.Fatalf("ListPools failed: %v", err)
+	}
+	for _, p := range *pools {
+		if p.NumOfVolumes == 0 {
+			continue
+		}
+		if _, err := testConf.poolOp.DeletePool(ctx, p.ID, false); !errors.Is(err, ErrPoolNotEmpty) {
+			t.Fatalf("DeletePool(%s) with %d volumes should fail with ErrPoolNotEmpty: %v", p.ID, p.NumOfVolumes, err)
+		}
+		break
+	}
+
+	fmt.Println("poolLifecycleTest Leave")
+}
+
+func canFitTest(t *testing.T) {
+	fmt.Println("canFitTest Enter")
+
+	pools, err := testConf.poolOp.ListPools(ctx)
+	if err != nil {
+		t.Fatalf("ListPools failed: %v", err)
+	}
+	if len(*pools) == 0 {
+		fmt.Println("canFitTest Leave")
+		return
+	}
+
+	pool := (*pools)[0]
+	fmt.Printf("  Pool %s: total %d, free %d, subscribed %d\n", pool.ID, pool.TotalSize, pool.FreeSize, pool.SubscribedSize)
+	fit, err := testConf.poolOp.CanFit(ctx, pool.ID, pool.TotalSize+1, pool.Provision)
+	if err != nil {
+		t.Fatalf("CanFit failed: %v", err)
+	}
+	if fit {
+		t.Fatalf("CanFit with size larger than pool %s should be false", pool.ID)
+	}
+	if pool.FreeSize > 0 {
+		if fit, err = testConf.poolOp.CanFit(ctx, pool.ID, 1, pool.Provision); err != nil || !fit {
+			t.Fatalf("CanFit of size 1 in pool %s with free %d should be true: %v", pool.ID, pool.FreeSize, err)
+		}
+	}
+
+	fmt.Println("canFitTest Leave")
+}
+
+func tieringTest(t *testing.T) {
+	fmt.Println("tieringTest Enter")
+
+	pools, err := testConf.poolOp.ListPools(ctx)
+	if err != nil {
+		t.Fatalf("ListPools failed: %v", err)
+	}
+	for _, p := range *pools {
+		if !p.AutoTiering {
+			continue
+		}
+
+		tiers, err := testConf.poolOp.ListTiers(ctx, p.ID)
+		if err != nil {
+			t.Fatalf("ListTiers(%s) failed: %v", p.ID, err)
+		}
+		fmt.Printf("  Pool %s tiers: %+v\n", p.ID, *tiers)
+
+		setting, err := testConf.poolOp.GetTieringSetting(ctx, p.ID)
+		if err != nil {
+			t.Fatalf("GetTieringSetting(%s) failed: %v", p.ID, err)
+		}
+		fmt.Printf("  Pool %s tiering: %+v\n", p.ID, setting)
+
+		if _, err := testConf.poolOp.GetRelocation(ctx, p.ID); err != nil {
+			t.Fatalf("GetRelocation(%s) failed: %v", p.ID, err)
+		}
+		break
+	}
+
+	fmt.Println("tieringTest Leave")
+}
+
+func poolTaskTest(t *testing.T) {
+	fmt.Println("poolTaskTest Enter")
+
+	tasks, err := testConf.poolOp.ListPoolTasks(ctx, testConf.poolId)
+	if err != nil {
+		t.Fatalf("ListPoolTasks failed: %v", err)
+	}
+	for _, task := range *tasks {
+		fmt.Printf("  Task %s %s: %s %d%%, remaining %v\n", task.ID, task.Type, task.State, task.Progress, task.Remaining())
+	}
+
+	if _, err := testConf.poolOp.StartScan(ctx, testConf.poolId, PoolTaskRebuild, false); err == nil {
+		t.Fatalf("StartScan with REBUILD should fail")
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	pool, err := testConf.poolOp.WaitPoolOptimal(waitCtx, testConf.poolId, 5*time.Second, nil)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitPoolOptimal failed: %v", err)
+	}
+	fmt.Printf("  WaitPoolOptimal: %+v, %v\n", pool, err)
+
+	fmt.Println("poolTaskTest Leave")
+}
+
+func TestRankPools(t *testing.T) {
+	pools := []PoolData{
+		{ID: "P1", Provision: ProvisionThick, RaidLevel: RAID5, NumOfVolumes: 5, TotalSize: 1000, FreeSize: 600, SubscribedSize: 400},
+		{ID: "P2", Provision: ProvisionThick, RaidLevel: RAID6, NumOfVolumes: 1, TotalSize: 1000, FreeSize: 300, SubscribedSize: 700},
+		{ID: "P3", Provision: ProvisionThin, RaidLevel: RAID5, NumOfVolumes: 2, TotalSize: 1000, FreeSize: 900, SubscribedSize: 3000},
+		{ID: "P4", Provision: ProvisionThick, RaidLevel: RAID5, NumOfVolumes: 0, TotalSize: 1000, FreeSize: 50},
+	}
+	tiering := true
+
+	tests := []struct {
+		name   string
+		c      PlacementConstraints
+		scorer PoolScorer
+		want   []string // eligible pools in order
+	}{
+		{"most free thick", PlacementConstraints{Size: 100, Provision: ProvisionThick}, ScoreMostFree, []string{"P1", "P2"}},
+		{"least volumes", PlacementConstraints{Size: 100, Provision: ProvisionThick}, ScoreLeastVolumes, []string{"P2", "P1"}},
+		{"spread", PlacementConstraints{Size: 100}, ScoreSpread, []string{"P1", "P2", "P3"}},
+		{"raid level", PlacementConstraints{Size: 100, RaidLevels: []string{RAID6}}, nil, []string{"P2"}},
+		{"min free", PlacementConstraints{Size: 100, MinFreePercent: 30}, nil, []string{"P3", "P1"}},
+		{"include exclude", PlacementConstraints{Size: 10, IncludePoolIDs: []string{"P1", "P4"}, ExcludePoolIDs: []string{"P1"}}, nil, []string{"P4"}},
+		{"auto tiering", PlacementConstraints{Size: 10, AutoTiering: &tiering}, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		got := []string{}
+		for _, cand := range rankPools(pools, &tt.c, tt.scorer) {
+			if len(cand.Reasons) == 0 {
+				t.Errorf("%s: pool %s has no reason", tt.name, cand.Pool.ID)
+			}
+			if cand.Eligible {
+				got = append(got, cand.Pool.ID)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	// Nil constraints accept every pool
+	got := []string{}
+	for _, cand := range rankPools(pools, nil, nil) {
+		if cand.Eligible {
+			got = append(got, cand.Pool.ID)
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint([]string{"P3", "P1", "P2", "P4"}) {
+		t.Errorf("nil constraints: got %v, want [P3 P1 P2 P4]", got)
+	}
+}
+
+func TestCreatePoolParam(t *testing.T) {
+	groups := []DiskGroupParam{{DiskIDs: []string{"1", "2", "3"}}}
+
+	tests := []struct {
+		name  string
+		param PoolCreateParam
+	}{
+		{"no provision", PoolCreateParam{RaidLevel: RAID5, DiskGroups: groups}},
+		{"unknown provision", PoolCreateParam{RaidLevel: RAID5, Provision: "SPARSE", DiskGroups: groups}},
+		{"unknown raid level", PoolCreateParam{RaidLevel: "RAID7", Provision: ProvisionThin, DiskGroups: groups}},
+		{"too few disks", PoolCreateParam{RaidLevel: RAID6, Provision: ProvisionThick, DiskGroups: groups}},
+		{"different group sizes", PoolCreateParam{RaidLevel: RAID5, Provision: ProvisionThick,
+			DiskGroups: []DiskGroupParam{{DiskIDs: []string{"1", "2", "3"}}, {DiskIDs: []string{"4", "5", "6", "7"}}}}},
+		{"duplicate disk", PoolCreateParam{RaidLevel: RAID1, Provision: ProvisionThick,
+			DiskGroups: []DiskGroupParam{{DiskIDs: []string{"1", "2"}}, {DiskIDs: []string{"2", "3"}}}}},
+	}
+
+	// The parameters are checked before any request is sent
+	op := &PoolOp{}
+	for _, tt := range tests {
+		if _, err := op.CreatePool(context.Background(), &tt.param); err == nil {
+			t.Errorf("%s: CreatePool should fail", tt.name)
+		}
+	}
+}
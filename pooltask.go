@@ -0,0 +1,163 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// PoolTaskType is the type of a pool background task
+type PoolTaskType string
+
+const (
+	PoolTaskRebuild     PoolTaskType = "REBUILD"
+	PoolTaskMigration   PoolTaskType = "MIGRATION" // RAID level change
+	PoolTaskExpansion   PoolTaskType = "EXPANSION" // disk group expansion
+	PoolTaskParityCheck PoolTaskType = "PARITY_CHECK"
+	PoolTaskMediaScan   PoolTaskType = "MEDIA_SCAN"
+)
+
+// Background I/O priorities
+const (
+	BgPriorityHigh   = "HIGH"
+	BgPriorityMedium = "MEDIUM"
+	BgPriorityLow    = "LOW"
+)
+
+// return value of GET /rest/v2/storage/pools/_poolId/tasks
+type PoolTaskData struct {
+	ID          string       `json:"id"`
+	Type        PoolTaskType `json:"type"`
+	State       TaskState    `json:"state"`
+	Progress    int          `json:"progress"`
+	ETA         int64        `json:"eta"` // seconds, 0 if unknown
+	DiskGroupID string       `json:"diskGroupId"`
+	DiskID      string       `json:"diskId"` // the disk being rebuilt
+	StartTime   int64        `json:"startTime"`
+}
+
+// Finished returns true if the task is done or failed
+func (t *PoolTaskData) Finished() bool {
+	return t.State == TaskDone || t.State == TaskFailed
+}
+
+// Remaining returns the estimated remaining time of the task, or 0 if unknown
+func (t *PoolTaskData) Remaining() time.Duration {
+	return time.Duration(t.ETA) * time.Second
+}
+
+// ListPoolTasks list background tasks of a pool
+func (v *PoolOp) ListPoolTasks(ctx context.Context, poolId string) (*[]PoolTaskData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/pools/"+poolId+"/tasks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []PoolTaskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// StartScan starts a parity check or media scan of a pool. autoFix repairs inconsistent parity found by a parity check.
+// POST /rest/v2/storage/pools/_poolId/tasks
+func (v *PoolOp) StartScan(ctx context.Context, poolId string, scanType PoolTaskType, autoFix bool) (*PoolTaskData, error) {
+	if scanType != PoolTaskParityCheck && scanType != PoolTaskMediaScan {
+		return nil, fmt.Errorf("%s is not a scan", scanType)
+	}
+
+	param := struct {
+		Type    PoolTaskType `json:"type"`
+		AutoFix bool         `json:"autoFix,omitempty"`
+	}{scanType, autoFix}
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/storage/pools/"+poolId+"/tasks", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := PoolTaskData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetBackgroundPriority set the I/O priority of background tasks of a pool
+// PATCH /rest/v2/storage/pools/_poolId
+func (v *PoolOp) SetBackgroundPriority(ctx context.Context, poolId, priority string) (*PoolData, error) {
+
+	param := struct {
+		BgIoPriority string `json:"bgIoPriority"`
+	}{priority}
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/storage/pools/"+poolId, string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := PoolData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WaitPoolOptimal polls a pool until its health is GOOD and no rebuild, migration or expansion is running.
+// progress is called with the running tasks on every poll if not nil.
+func (v *PoolOp) WaitPoolOptimal(ctx context.Context, poolId string, interval time.Duration, progress func([]PoolTaskData)) (*PoolData, error) {
+	if interval <= 0 {
+		interval = defaultTaskPollInterval
+	}
+
+	for {
+		pool, err := v.ListPoolByID(ctx, poolId)
+		if err != nil {
+			return nil, err
+		}
+		if pool.Health == HealthFailed {
+			return pool, fmt.Errorf("pool %s is FAILED", poolId)
+		}
+
+		tasks, err := v.ListPoolTasks(ctx, poolId)
+		if err != nil {
+			return nil, err
+		}
+		running := []PoolTaskData{}
+		for _, t := range *tasks {
+			if !t.Finished() {
+				running = append(running, t)
+			}
+		}
+		if progress != nil {
+			progress(running)
+		}
+
+		if pool.Health == HealthGood && !hasRecoveryTask(running) {
+			return pool, nil
+		}
+		glog.V(3).Infof("[WaitPoolOptimal] pool %s is %s with %d running tasks\n", poolId, pool.Health, len(running))
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return pool, err
+		}
+	}
+}
+
+// Scans do not affect the pool health, only wait for rebuild, migration and expansion
+func hasRecoveryTask(tasks []PoolTaskData) bool {
+	for _, t := range tasks {
+		if t.Type == PoolTaskRebuild || t.Type == PoolTaskMigration || t.Type == PoolTaskExpansion {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Default polling interval of waiting for controllers
+const defaultPowerPollInterval = 15 * time.Second
+
+var (
+	// ErrConfirmationMismatch is returned if PowerOptions.Confirm is not the serial number of the storage
+	ErrConfirmationMismatch = errors.New("confirmation token does not match the storage serial number")
+	// ErrPreflightFailed is returned if the storage is not safe to reboot or shut down
+	ErrPreflightFailed = errors.New("pre-flight check failed")
+)
+
+// PowerOptions are options of RebootController and ShutdownController.
+type PowerOptions struct {
+	// ControllerID is the controller to reboot or shut down. Empty means both controllers.
+	ControllerID string
+	// Confirm must be the SerialNumber of AboutData, to prevent powering off a storage by accident.
+	Confirm string
+	// Force skips pre-flight checks
+	Force bool
+	// Wait until the controllers are back online. Only for reboot.
+	Wait         bool
+	PollInterval time.Duration
+}
+
+// PreflightCheck checks that no rebuild is in progress, no volume is initializing,
+// and the other controller is healthy if only one controller is going down.
+func (s *SystemOp) PreflightCheck(ctx context.Context, controllerID string) error {
+	client, err := s.auth()
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+
+	disks, err := NewDisk(client).ListDisks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range *disks {
+		if d.State == "REBUILDING" {
+			problems = append(problems, fmt.Sprintf("disk %s (enclosure %s slot %d) is rebuilding", d.ID, d.EnclosureID, d.Slot))
+		}
+	}
+
+	vols, err := NewVolume(client).ListVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range *vols {
+		if v.State == "INITIALIZING" {
+			problems = append(problems, fmt.Sprintf("volume %s is initializing (%d%%)", v.Name, v.Progress))
+		}
+	}
+
+	if controllerID != "" {
+		ctrls, err := s.ListControllers(ctx)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, c := range *ctrls {
+			if c.ID == controllerID {
+				found = true
+			} else if c.Status != HealthGood {
+				problems = append(problems, fmt.Sprintf("other controller %s is %s", c.ID, c.Status))
+			}
+		}
+		if !found {
+			return fmt.Errorf("controller %s not found", controllerID)
+		}
+		if len(*ctrls) < 2 {
+			problems = append(problems, "no other controller to take over")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrPreflightFailed, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// RebootController reboots one or both controllers after confirmation and pre-flight checks.
+// POST /rest/v2/system/controllers/_controllerID/reboot
+// POST /rest/v2/system/reboot
+func (s *SystemOp) RebootController(ctx context.Context, opts *PowerOptions) error {
+	before, err := s.preparePower(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sendPowerAction(ctx, opts.ControllerID, "reboot"); err != nil {
+		return err
+	}
+
+	if !opts.Wait {
+		return nil
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPowerPollInterval
+	}
+	return s.waitRebooted(ctx, before, interval)
+}
+
+// ShutdownController shuts down one or both controllers after confirmation and pre-flight checks.
+// POST /rest/v2/system/controllers/_controllerID/shutdown
+// POST /rest/v2/system/shutdown
+func (s *SystemOp) ShutdownController(ctx context.Context, opts *PowerOptions) error {
+	if _, err := s.preparePower(ctx, opts); err != nil {
+		return err
+	}
+
+	return s.sendPowerAction(ctx, opts.ControllerID, "shutdown")
+}
+
+// Check confirmation and pre-flight, and returns uptime of the target controllers
+func (s *SystemOp) preparePower(ctx context.Context, opts *PowerOptions) (map[string]uint64, error) {
+	if _, err := s.auth(); err != nil {
+		return nil, err
+	}
+
+	about, err := s.GetAbout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Confirm == "" || opts.Confirm != about.SerialNumber {
+		return nil, ErrConfirmationMismatch
+	}
+
+	if !opts.Force {
+		if err := s.PreflightCheck(ctx, opts.ControllerID); err != nil {
+			return nil, err
+		}
+	}
+
+	ctrls, err := s.ListControllers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uptimes := map[string]uint64{}
+	for _, c := range *ctrls {
+		if opts.ControllerID == "" || c.ID == opts.ControllerID {
+			uptimes[c.ID] = c.Uptime
+		}
+	}
+
+	return uptimes, nil
+}
+
+func (s *SystemOp) sendPowerAction(ctx context.Context, controllerID, action string) error {
+	client, err := s.auth()
+	if err != nil {
+		return err
+	}
+
+	path := "/rest/v2/system/" + action
+	if controllerID != "" {
+		path = "/rest/v2/system/controllers/" + controllerID + "/" + action
+	}
+	glog.V(2).Infof("[%s] %s\n", action, path)
+
+	req, err := client.NewRequest(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Wait until every controller has restarted, which is known by its uptime, and is healthy again
+func (s *SystemOp) waitRebooted(ctx context.Context, before map[string]uint64, interval time.Duration) error {
+	for {
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+
+		ctrls, err := s.ListControllers(ctx)
+		if err != nil {
+			glog.V(3).Infof("[waitRebooted] controllers are not ready: %v\n", err)
+			continue
+		}
+
+		ctrlMap := map[string]ControllerData{}
+		for _, c := range *ctrls {
+			ctrlMap[c.ID] = c
+		}
+
+		// A controller which is not listed yet is still restarting
+		done := true
+		for id, uptime := range before {
+			c, ok := ctrlMap[id]
+			if !ok || c.Uptime >= uptime || c.Status != HealthGood {
+				glog.V(3).Infof("[waitRebooted] controller %s is not ready\n", id)
+				done = false
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// return value of GET /rest/v2/system/maintenance
+type MaintenanceData struct {
+	Enable bool   `json:"enable"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// GetMaintenanceMode get the maintenance mode of the storage
+func (s *SystemOp) GetMaintenanceMode(ctx context.Context) (*MaintenanceData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/maintenance", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := MaintenanceData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetMaintenanceMode enables or disables the maintenance mode, which suppresses alerts during power work
+// PUT /rest/v2/system/maintenance
+func (s *SystemOp) SetMaintenanceMode(ctx context.Context, param *MaintenanceData) (*MaintenanceData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := client.NewRequest(ctx, http.MethodPut, "/rest/v2/system/maintenance", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := MaintenanceData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -5,13 +5,14 @@ package goqsan
 import (
 	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -22,11 +23,22 @@ const (
 	defaultHttpsPort = 443
 )
 
+// ErrClientClosed is returned by requests of an AuthClient after Close or Logout
+var ErrClientClosed = errors.New("auth client is closed")
+
+// ErrCredentialScopes is returned by GetAuthClientWithCredentials if scopes has an encoded credential part
+var ErrCredentialScopes = errors.New("scopes with an encoded credential need GetAuthClientWithScopes")
+
+// ErrReadOnly is returned when a read-only AuthClient sends a mutating request
+var ErrReadOnly = errors.New("auth client is read-only")
+
 // QSAN client without authentication
 type Client struct {
 	apiKey     string
 	baseURL    string
 	HTTPClient *http.Client
+	tokenStore TokenStore
+	reqLog     *requestLog
 }
 
 // ClientOptions are options for QSAN http client.
@@ -34,14 +46,27 @@ type ClientOptions struct {
 	Https      bool
 	Port       int
 	ReqTimeout time.Duration
+	// TokenStore persists tokens of AuthClient across processes. Optional.
+	// Close clients by AuthClient.Release to keep their tokens, Logout deletes and revokes them.
+	TokenStore TokenStore
+	// RequestLogSize is the number of latest requests kept for diagnostics.
+	// Zero means the default size, and negative disables the request log.
+	RequestLogSize int
 }
 
 // QSAN client with authentication
 type AuthClient struct {
 	Client
-	user, passwd, scopes string
-	accessToken          string
-	refreshToken         string
+	creds        CredentialProvider
+	scopes       string
+	scopeSet     *Scopes
+	readOnly     bool
+	accessToken  string
+	refreshToken string
+	tokenKey     string
+
+	mu     sync.Mutex
+	closed bool
 }
 
 // For authentication
@@ -104,21 +129,24 @@ func NewClient(ip string, opts ClientOptions) *Client {
 	if opts.ReqTimeout != 0 {
 		client.HTTPClient.Timeout = opts.ReqTimeout
 	}
+	client.tokenStore = opts.TokenStore
+	client.reqLog = newRequestLog(opts.RequestLogSize)
 
 	return client
 }
 
 func GetCSIScopes(passwd string) string {
-	key := make([]byte, 32) //  32 bytes for AES-256
-	copy(key[:], "qsanscope1234")
-	enc := AESECBEncrypt([]byte(passwd), key)
-	enc64 := base64.StdEncoding.EncodeToString(enc)
+	scopes, err := NewScopes(ScopeCSIReadWrite).WithCredential(nil).Encode(passwd)
+	if err != nil {
+		glog.Errorf("[GetCSIScopes] %v\n", err)
+	}
 
-	return fmt.Sprintf("%s|%s", "csi.readwrite", enc64)
+	return scopes
 }
 
 // If body format is url.Values, then body data will be sent using x-www-form-urlencoded format.
 // If body format is string, then body data will be sent using raw data with JSON format.
+// If body format is io.Reader, then body data will be streamed using octet-stream format.
 func (c *Client) NewRequest(ctx context.Context, method, urlPath string, body interface{}) (*http.Request, error) {
 	var (
 		req *http.Request
@@ -142,8 +170,12 @@ func (c *Client) NewRequest(ctx context.Context, method, urlPath string, body in
 			// raw data
 			req, err = http.NewRequest(method, u.String(), strings.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
+		case io.Reader:
+			// streaming data, the caller may override Content-Type
+			req, err = http.NewRequest(method, u.String(), body)
+			req.Header.Set("Content-Type", "application/octet-stream")
 		default:
-			return nil, fmt.Errorf("Unknow body format! Only url.Values and string formats are supported.\n")
+			return nil, fmt.Errorf("Unknow body format! Only url.Values, string and io.Reader formats are supported.\n")
 		}
 	} else {
 		req, err = http.NewRequest(method, u.String(), nil)
@@ -158,6 +190,13 @@ func (c *Client) NewRequest(ctx context.Context, method, urlPath string, body in
 }
 
 func (c *AuthClient) SendRequest(ctx context.Context, req *http.Request, v interface{}) error {
+	if c.isClosed() {
+		return ErrClientClosed
+	}
+	if c.readOnly && isMutatingRequest(req) {
+		return fmt.Errorf("%w: %s %s", ErrReadOnly, req.Method, req.URL.Path)
+	}
+
 	resterr := RestError{ReqMethod: req.Method, ReqUrl: req.Host + req.URL.Path}
 	res, err := c.doSendRequest(ctx, req, v)
 	if err != nil {
@@ -181,12 +220,13 @@ func (c *AuthClient) SendRequest(ctx context.Context, req *http.Request, v inter
 			// Update new access token then send request again
 			c.accessToken = authRes.AccessToken
 			c.apiKey = authRes.AccessToken
+			c.saveToken(authRes)
 			glog.V(2).Infof("[AuthSendRequest] SendRequest again (%s %s%s)\n", req.Method, req.Host, req.URL.Path)
 			res, err = c.doSendRequest(ctx, req, v)
 		} else {
 			// When refresh token expired, renew a new access token and refresh token.
 			glog.V(2).Infof("[AuthSendRequest] renew new access token and refresh token.\n")
-			res, err := c.login(ctx, c.user, c.passwd, c.scopes)
+			res, err := c.relogin(ctx)
 			if err != nil {
 				resterr.Err = fmt.Errorf("renew access token failed: %v\n", err)
 				return &resterr
@@ -196,6 +236,7 @@ func (c *AuthClient) SendRequest(ctx context.Context, req *http.Request, v inter
 			c.accessToken = res.AccessToken
 			c.apiKey = res.AccessToken
 			c.refreshToken = res.RefreshToken
+			c.saveToken(res)
 
 			authRes, ok := v.(*AuthRes)
 			if ok {
@@ -234,6 +275,72 @@ func (c *AuthClient) SendRequest(ctx context.Context, req *http.Request, v inter
 
 }
 
+// SendRawRequest sends the request and returns the response without decoding, for streaming data.
+// The caller must close the response body. A non-2xx response is returned as RestError.
+// If the access token expired, the request is sent again only if its body can be rewound.
+func (c *AuthClient) SendRawRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if c.readOnly && isMutatingRequest(req) {
+		return nil, fmt.Errorf("%w: %s %s", ErrReadOnly, req.Method, req.URL.Path)
+	}
+
+	resterr := RestError{ReqMethod: req.Method, ReqUrl: req.Host + req.URL.Path}
+	res, err := c.doSendRequest(ctx, req, nil)
+	if err != nil {
+		resterr.Err = err
+		return nil, &resterr
+	}
+
+	if res.StatusCode == http.StatusUnauthorized {
+		res.Body.Close()
+
+		if req.Body != nil && req.GetBody == nil {
+			resterr.StatusCode = res.StatusCode
+			resterr.Err = errors.New("access token expired and the request body cannot be sent again")
+			return nil, &resterr
+		}
+
+		glog.V(2).Infof("[SendRawRequest] generate new access token. (%s %s%s)\n", req.Method, req.Host, req.URL.Path)
+		authRes, err := c.genAccessToken(ctx, c.refreshToken)
+		if err != nil {
+			resterr.Err = fmt.Errorf("genAccessToken failed: %v\n", err)
+			return nil, &resterr
+		}
+		c.accessToken = authRes.AccessToken
+		c.apiKey = authRes.AccessToken
+		c.saveToken(authRes)
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				resterr.Err = err
+				return nil, &resterr
+			}
+		}
+		if res, err = c.doSendRequest(ctx, req, nil); err != nil {
+			resterr.Err = err
+			return nil, &resterr
+		}
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+
+		resterr.StatusCode = res.StatusCode
+		errRes := errorResponse{}
+		if err = json.NewDecoder(res.Body).Decode(&errRes); err == nil {
+			glog.Warningf("[SendRawRequest] %s %s%s, StatusCode(%d) errRes: %+v\n", req.Method, req.Host, req.URL.Path, res.StatusCode, errRes)
+			resterr.ErrResp = errRes
+		} else {
+			resterr.Err = fmt.Errorf("unknown error, status code: %d", res.StatusCode)
+		}
+		return nil, &resterr
+	}
+
+	return res, nil
+}
+
 func (c *Client) SendRequest(ctx context.Context, req *http.Request, v interface{}) error {
 	res, err := c.doSendRequest(ctx, req, v)
 	if err != nil {
@@ -266,11 +373,17 @@ func (c *Client) doSendRequest(ctx context.Context, req *http.Request, v interfa
 	}
 
 	req = req.WithContext(ctx)
+	start := time.Now()
 	res, err := c.HTTPClient.Do(req)
+	entry := RequestLogEntry{Time: start, Method: req.Method, URL: req.Host + req.URL.Path, Duration: time.Since(start)}
 	if err != nil {
 		glog.Errorf("[doSendRequest] err: %v\n", err)
+		entry.Error = err.Error()
+		c.reqLog.add(entry)
 		return nil, err
 	}
+	entry.StatusCode = res.StatusCode
+	c.reqLog.add(entry)
 
 	glog.V(4).Infof("[doSendRequest] StatusCode: %d (%s%s)\n", res.StatusCode, req.Host, req.URL.Path)
 	return res, nil
@@ -314,24 +427,265 @@ func (c *AuthClient) genAccessToken(ctx context.Context, t string) (*AuthRes, er
 	return &res, nil
 }
 
+// Login again with the latest credentials from the credential provider
+func (c *AuthClient) relogin(ctx context.Context) (*AuthRes, error) {
+	creds, err := c.creds.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials failed: %v", err)
+	}
+
+	scopes := c.scopes
+	if c.scopeSet != nil {
+		// The credential part of scopes follows the rotated password
+		if scopes, err = c.scopeSet.Encode(creds.Password); err != nil {
+			return nil, err
+		}
+		c.scopes = scopes
+	}
+
+	return c.login(ctx, creds.User, creds.Password, scopes)
+}
+
+// GetAuthClient returns QSAN client with authentication by fixed user and password.
+// Scopes from GetCSIScopes are encoded again if the password is changed by ChangeOwnPassword.
 func (c *Client) GetAuthClient(ctx context.Context, user, passwd, scopes string) (*AuthClient, error) {
-	res, err := c.login(ctx, user, passwd, scopes)
+	cp := NewStaticCredentials(user, passwd)
+	creds, err := cp.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials failed: %v\n", err)
+	}
+
+	authClient, err := c.getAuthClient(ctx, cp, creds, scopes)
+	if err != nil {
+		return nil, err
+	}
+	authClient.scopeSet = parseCredentialScopes(scopes, passwd)
+
+	return authClient, nil
+}
+
+// GetAuthClientWithCredentials returns QSAN client with authentication.
+// Credentials are retrieved from the provider whenever the client has to login again.
+// scopes must not have an encoded credential part, which cannot follow a rotated password.
+// Use GetAuthClientWithScopes for such scopes.
+func (c *Client) GetAuthClientWithCredentials(ctx context.Context, cp CredentialProvider, scopes string) (*AuthClient, error) {
+	if hasCredential(scopes) {
+		return nil, ErrCredentialScopes
+	}
+
+	creds, err := cp.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials failed: %v\n", err)
+	}
+
+	return c.getAuthClient(ctx, cp, creds, scopes)
+}
+
+// GetAuthClientWithScopes returns QSAN client with authentication by the given scopes.
+// The client is read-only if only read-only scopes are requested.
+func (c *Client) GetAuthClientWithScopes(ctx context.Context, cp CredentialProvider, scopeSet *Scopes) (*AuthClient, error) {
+	creds, err := cp.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials failed: %v\n", err)
+	}
+
+	scopes, err := scopeSet.Encode(creds.Password)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scopes: %v\n", err)
+	}
+
+	authClient, err := c.getAuthClient(ctx, cp, creds, scopes)
+	if err != nil {
+		return nil, err
+	}
+	authClient.scopeSet = scopeSet
+	authClient.readOnly = scopeSet.ReadOnly()
+
+	return authClient, nil
+}
+
+func (c *Client) getAuthClient(ctx context.Context, cp CredentialProvider, creds *Credentials, scopes string) (*AuthClient, error) {
+	// Tokens of different scopes must not be shared, e.g. a read-only token by a read-write client.
+	// The credential part is left out since it changes with the password.
+	tokenKey := c.baseURL + "|" + creds.User + "|" + scopeNames(scopes)
+	if c.tokenStore != nil {
+		token, err := c.tokenStore.Load(tokenKey)
+		if err != nil {
+			glog.Warningf("[GetAuthClient] load cached token failed: %v\n", err)
+		} else if token.Valid() {
+			glog.V(3).Infof("[GetAuthClient] reuse cached token of %s\n", tokenKey)
+			return c.newAuthClient(cp, scopes, tokenKey, token.AccessToken, token.RefreshToken), nil
+		}
+	}
+
+	res, err := c.login(ctx, creds.User, creds.Password, scopes)
 	if err != nil {
 		return nil, fmt.Errorf("login failed: %v\n", err)
 	}
 
 	glog.V(3).Infof("AccessToken: %s\n", res.AccessToken)
 
+	authClient := c.newAuthClient(cp, scopes, tokenKey, res.AccessToken, res.RefreshToken)
+	authClient.saveToken(res)
+
+	return authClient, nil
+}
+
+func (c *Client) newAuthClient(cp CredentialProvider, scopes, tokenKey, accessToken, refreshToken string) *AuthClient {
 	return &AuthClient{
 		Client: Client{
-			apiKey:     res.AccessToken,
+			apiKey:     accessToken,
 			baseURL:    c.baseURL,
 			HTTPClient: c.HTTPClient,
+			tokenStore: c.tokenStore,
+			reqLog:     c.reqLog,
 		},
-		user:         user,
-		passwd:       passwd,
+		creds:        cp,
 		scopes:       scopes,
-		accessToken:  res.AccessToken,
-		refreshToken: res.RefreshToken,
-	}, nil
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+		tokenKey:     tokenKey,
+	}
+}
+
+// Logout revokes the access token and refresh token on the storage, and deletes them from the token store,
+// then later requests of the client fail with ErrClientClosed.
+// Use Release instead if the session should be reused from the token store by a later process.
+func (c *AuthClient) Logout(ctx context.Context) error {
+	if !c.markClosed() {
+		return nil
+	}
+
+	if c.tokenStore != nil {
+		if err := c.tokenStore.Delete(c.tokenKey); err != nil {
+			glog.Warningf("[Logout] delete cached token failed: %v\n", err)
+		}
+	}
+
+	// Revoke both tokens explicitly, without relying on the storage to revoke the access token with its refresh token
+	params := url.Values{}
+	params.Add("accessToken", c.accessToken)
+	params.Add("refreshToken", c.refreshToken)
+
+	req, err := c.NewRequest(ctx, http.MethodPost, "/auth/revoke", params)
+	if err != nil {
+		return err
+	}
+
+	// Do not use AuthClient.SendRequest, an expired session needs no login again just to be revoked.
+	res := EmptyData{}
+	err = c.Client.SendRequest(ctx, req, &res)
+	c.accessToken, c.refreshToken, c.apiKey = "", "", ""
+	if err != nil {
+		return fmt.Errorf("revoke token failed: %v", err)
+	}
+
+	return nil
+}
+
+// Close is the same as Logout with background context
+func (c *AuthClient) Close() error {
+	return c.Logout(context.Background())
+}
+
+// Release closes the client without revoking its tokens, so that they stay valid in the token store
+// until they expire. Later requests of the client fail with ErrClientClosed.
+// Without a token store, the session is left to expire on the storage, so Logout should be used.
+func (c *AuthClient) Release() {
+	c.markClosed()
+}
+
+// Returns false if the client was already closed
+func (c *AuthClient) markClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.closed = true
+	return true
+}
+
+// SetReadOnly enables or disables read-only mode.
+// In read-only mode, mutating requests fail with ErrReadOnly before they are sent.
+func (c *AuthClient) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// ReadOnly returns true if the client is in read-only mode
+func (c *AuthClient) ReadOnly() bool {
+	return c.readOnly
+}
+
+// Authentication requests are always allowed in read-only mode
+func isMutatingRequest(req *http.Request) bool {
+	if strings.HasPrefix(req.URL.Path, "/auth/") {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	return true
+}
+
+func (c *AuthClient) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+// WithSession runs fn with a new AuthClient, and always logs out when fn returns.
+// The error of fn takes precedence over the error of logout.
+// Scopes from GetCSIScopes are accepted and encoded again with the password of every login.
+func (c *Client) WithSession(ctx context.Context, cp CredentialProvider, scopes string, fn func(*AuthClient) error) error {
+	authClient, err := c.sessionClient(ctx, cp, scopes)
+	if err != nil {
+		return err
+	}
+
+	fnErr := fn(authClient)
+	// Logout even if ctx was cancelled inside fn
+	logoutErr := authClient.Logout(context.Background())
+	if fnErr != nil {
+		if logoutErr != nil {
+			glog.Warningf("[WithSession] %v\n", logoutErr)
+		}
+		return fnErr
+	}
+
+	return logoutErr
+}
+
+// Scopes with an encoded credential are decoded to a Scopes, which GetAuthClientWithScopes can encode again
+func (c *Client) sessionClient(ctx context.Context, cp CredentialProvider, scopes string) (*AuthClient, error) {
+	if !hasCredential(scopes) {
+		return c.GetAuthClientWithCredentials(ctx, cp, scopes)
+	}
+
+	creds, err := cp.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials failed: %v\n", err)
+	}
+	scopeSet := parseCredentialScopes(scopes, creds.Password)
+	if scopeSet == nil {
+		return nil, fmt.Errorf("%w: the credential does not match the current password", ErrCredentialScopes)
+	}
+
+	return c.GetAuthClientWithScopes(ctx, cp, scopeSet)
+}
+
+// Persist the tokens to the token store if there is one
+func (c *AuthClient) saveToken(res *AuthRes) {
+	if c.tokenStore == nil {
+		return
+	}
+
+	if err := c.tokenStore.Save(c.tokenKey, newCachedToken(res, c.refreshToken)); err != nil {
+		glog.Warningf("[AuthClient] save token failed: %v\n", err)
+	}
 }
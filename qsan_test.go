@@ -0,0 +1,212 @@
+package goqsan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeStorage serves the authentication API and answers other requests from responses,
+// or with an empty list if the path is not in responses
+type fakeStorage struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	responses  map[string]string // path to response body
+	requests   []string          // method and path of every request
+	logins     []string          // scopes of every login
+	revoked    []url.Values
+	failRevoke bool
+}
+
+func newFakeStorage(t *testing.T) *fakeStorage {
+	f := &fakeStorage{responses: map[string]string{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		if body, ok := f.responses[r.URL.Path]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		switch r.URL.Path {
+		case "/auth/get":
+			r.ParseForm()
+			f.logins = append(f.logins, r.PostForm.Get("scopes"))
+			n := len(f.logins)
+			fmt.Fprintf(w, `{"accessToken":"AT%d","expireTime":3600,"refreshToken":"RT%d"}`, n, n)
+		case "/auth/revoke":
+			r.ParseForm()
+			f.revoked = append(f.revoked, r.PostForm)
+			if f.failRevoke {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error":{"message":"revoke failed","code":1}}`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// Returns true if a request of the method and path was received
+func (f *fakeStorage) received(method, path string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, req := range f.requests {
+		if req == method+" "+path {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeStorage) client(t *testing.T) *Client {
+	u, _ := url.Parse(f.URL)
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(u.Hostname(), ClientOptions{Port: port})
+}
+
+func TestWithSessionCSIScopes(t *testing.T) {
+	f := newFakeStorage(t)
+	c := f.client(t)
+	cp := NewStaticCredentials("admin", "1234")
+
+	called := false
+	err := c.WithSession(context.Background(), cp, GetCSIScopes("1234"), func(ac *AuthClient) error {
+		called = true
+		_, err := NewPool(ac).ListPools(context.Background())
+		return err
+	})
+	if err != nil || !called {
+		t.Fatalf("WithSession returned %v, fn called: %t", err, called)
+	}
+	if len(f.logins) != 1 || f.logins[0] != GetCSIScopes("1234") {
+		t.Fatalf("login scopes are %v, expected %s", f.logins, GetCSIScopes("1234"))
+	}
+
+	// Scopes encoded with an old password are rejected, scopes of the current password are accepted
+	cp.SetPassword("5678")
+	err = c.WithSession(context.Background(), cp, GetCSIScopes("1234"), func(*AuthClient) error { return nil })
+	if !errors.Is(err, ErrCredentialScopes) {
+		t.Fatalf("WithSession with scopes of an old password returned %v", err)
+	}
+	if err := c.WithSession(context.Background(), cp, GetCSIScopes("5678"), func(*AuthClient) error { return nil }); err != nil {
+		t.Fatalf("WithSession with scopes of the current password failed: %v", err)
+	}
+	if last := f.logins[len(f.logins)-1]; last != GetCSIScopes("5678") {
+		t.Fatalf("login scopes are %s, expected %s", last, GetCSIScopes("5678"))
+	}
+}
+
+func TestLogout(t *testing.T) {
+	f := newFakeStorage(t)
+	ac, err := f.client(t).GetAuthClient(context.Background(), "admin", "1234", "")
+	if err != nil {
+		t.Fatalf("GetAuthClient failed: %v", err)
+	}
+
+	if err := ac.Logout(context.Background()); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if len(f.revoked) != 1 || f.revoked[0].Get("accessToken") != "AT1" || f.revoked[0].Get("refreshToken") != "RT1" {
+		t.Fatalf("revoked %v, expected AT1 and RT1", f.revoked)
+	}
+	if _, err := NewPool(ac).ListPools(context.Background()); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("ListPools after Logout returned %v, expected ErrClientClosed", err)
+	}
+
+	// Logout again is a no-op
+	if err := ac.Close(); err != nil {
+		t.Fatalf("Close after Logout failed: %v", err)
+	}
+	if len(f.revoked) != 1 {
+		t.Fatalf("revoked %d times, expected once", len(f.revoked))
+	}
+}
+
+func TestRelease(t *testing.T) {
+	f := newFakeStorage(t)
+	store, err := NewFileTokenStore(t.TempDir(), []byte("secret"))
+	if err != nil {
+		t.Fatalf("NewFileTokenStore failed: %v", err)
+	}
+	c := f.client(t)
+	c.tokenStore = store
+
+	ac, err := c.GetAuthClient(context.Background(), "admin", "1234", "")
+	if err != nil {
+		t.Fatalf("GetAuthClient failed: %v", err)
+	}
+	ac.Release()
+	if _, err := NewPool(ac).ListPools(context.Background()); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("ListPools after Release returned %v, expected ErrClientClosed", err)
+	}
+	if err := ac.Logout(context.Background()); err != nil || len(f.revoked) != 0 {
+		t.Fatalf("Logout after Release returned %v and revoked %v", err, f.revoked)
+	}
+
+	// The next client reuses the released token
+	ac, err = c.GetAuthClient(context.Background(), "admin", "1234", "")
+	if err != nil {
+		t.Fatalf("GetAuthClient failed: %v", err)
+	}
+	if len(f.logins) != 1 || ac.accessToken != "AT1" {
+		t.Fatalf("logged in %d times with token %s, expected the released token", len(f.logins), ac.accessToken)
+	}
+}
+
+func TestWithSessionErrors(t *testing.T) {
+	f := newFakeStorage(t)
+	c := f.client(t)
+	cp := NewStaticCredentials("admin", "1234")
+	fnErr := errors.New("fn failed")
+
+	var session *AuthClient
+	err := c.WithSession(context.Background(), cp, "", func(ac *AuthClient) error {
+		session = ac
+		return fnErr
+	})
+	if err != fnErr {
+		t.Fatalf("WithSession returned %v, expected the error of fn", err)
+	}
+	if !session.isClosed() || len(f.revoked) != 1 {
+		t.Fatalf("WithSession did not log out after fn failed")
+	}
+
+	// The error of fn takes precedence over the error of logout
+	f.mu.Lock()
+	f.failRevoke = true
+	f.mu.Unlock()
+	if err := c.WithSession(context.Background(), cp, "", func(*AuthClient) error { return fnErr }); err != fnErr {
+		t.Fatalf("WithSession returned %v, expected the error of fn", err)
+	}
+	if err := c.WithSession(context.Background(), cp, "", func(*AuthClient) error { return nil }); err == nil {
+		t.Fatalf("WithSession should return the error of logout")
+	}
+
+	// No session is created if login fails
+	called := false
+	err = c.WithSession(context.Background(), NewStaticCredentials("", ""), "", func(*AuthClient) error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Fatalf("WithSession without user returned %v, fn called: %t", err, called)
+	}
+}
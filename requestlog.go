@@ -0,0 +1,131 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Default number of requests kept by the request log of a client
+const defaultRequestLogSize = 100
+
+// RequestLogEntry is a request sent by the client. Request bodies and tokens are never recorded.
+type RequestLogEntry struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	URL        string        `json:"url"`
+	StatusCode int           `json:"statusCode"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// requestLog keeps the latest requests in a ring buffer
+type requestLog struct {
+	mu      sync.Mutex
+	entries []RequestLogEntry
+	start   int
+	count   int
+}
+
+func newRequestLog(size int) *requestLog {
+	if size == 0 {
+		size = defaultRequestLogSize
+	}
+	if size < 0 {
+		return nil
+	}
+	return &requestLog{entries: make([]RequestLogEntry, size)}
+}
+
+func (l *requestLog) add(e RequestLogEntry) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count < len(l.entries) {
+		l.entries[(l.start+l.count)%len(l.entries)] = e
+		l.count++
+		return
+	}
+	l.entries[l.start] = e
+	l.start = (l.start + 1) % len(l.entries)
+}
+
+func (l *requestLog) list() []RequestLogEntry {
+	if l == nil {
+		return []RequestLogEntry{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	res := make([]RequestLogEntry, l.count)
+	for i := 0; i < l.count; i++ {
+		res[i] = l.entries[(l.start+i)%len(l.entries)]
+	}
+	return res
+}
+
+// RequestLog returns the latest requests sent by the client, from oldest to newest
+func (c *Client) RequestLog() []RequestLogEntry {
+	return c.reqLog.list()
+}
+
+// WriteClientBundle writes a zip archive of the client configuration, request log
+// and system information, to be attached with the support bundle of the same incident.
+func (s *SystemOp) WriteClientBundle(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	timeout := time.Duration(0)
+	if s.client.HTTPClient != nil {
+		timeout = s.client.HTTPClient.Timeout
+	}
+	config := map[string]interface{}{
+		"baseURL":       s.client.baseURL,
+		"reqTimeout":    timeout.String(),
+		"tokenStore":    s.client.tokenStore != nil,
+		"authenticated": s.authClient != nil,
+		"time":          time.Now(),
+	}
+	if s.authClient != nil {
+		config["readOnly"] = s.authClient.ReadOnly()
+		config["closed"] = s.authClient.isClosed()
+	}
+
+	about := map[string]interface{}{}
+	if res, err := s.GetAbout(ctx); err != nil {
+		about["error"] = err.Error()
+	} else {
+		about["about"] = res
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"client.json", config},
+		{"requests.json", s.client.RequestLog()},
+		{"about.json", about},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
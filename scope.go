@@ -0,0 +1,142 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scope is an access scope requested on login
+type Scope string
+
+const (
+	ScopeReadOnly     Scope = "readonly"
+	ScopeReadWrite    Scope = "readwrite"
+	ScopeCSIReadOnly  Scope = "csi.readonly"
+	ScopeCSIReadWrite Scope = "csi.readwrite"
+	ScopeAdmin        Scope = "admin"
+)
+
+// The default key to encode the credential part of scopes
+const defaultScopeKey = "qsanscope1234"
+
+var supportedScopes = map[Scope]bool{
+	ScopeReadOnly:     true,
+	ScopeReadWrite:    true,
+	ScopeCSIReadOnly:  true,
+	ScopeCSIReadWrite: true,
+	ScopeAdmin:        true,
+}
+
+// Scopes composes the scopes string passed to login.
+// The format is "scope1,scope2" optionally followed by "|" and the encoded credential.
+type Scopes struct {
+	scopes     []Scope
+	credential bool
+	key        []byte
+}
+
+// NewScopes returns scopes with the given scope list
+func NewScopes(scopes ...Scope) *Scopes {
+	return &Scopes{scopes: scopes}
+}
+
+// WithCredential appends the credential part encoded by key.
+// If key is nil, the default key is used.
+func (s *Scopes) WithCredential(key []byte) *Scopes {
+	s.credential = true
+	if key == nil {
+		key = []byte(defaultScopeKey)
+	}
+	s.key = key
+	return s
+}
+
+// Validate checks that all scopes are supported
+func (s *Scopes) Validate() error {
+	if len(s.scopes) == 0 {
+		return errors.New("no scope specified")
+	}
+	for _, sc := range s.scopes {
+		if !supportedScopes[sc] {
+			return fmt.Errorf("unsupported scope %q", sc)
+		}
+	}
+	if s.credential && len(s.key) > 32 {
+		return fmt.Errorf("scope key is longer than 32 bytes")
+	}
+
+	return nil
+}
+
+// ReadOnly returns true if only read-only scopes are requested
+func (s *Scopes) ReadOnly() bool {
+	if len(s.scopes) == 0 {
+		return false
+	}
+	for _, sc := range s.scopes {
+		if sc != ScopeReadOnly && sc != ScopeCSIReadOnly {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Encode returns the scopes string. The password is only used if the credential part is enabled.
+func (s *Scopes) Encode(passwd string) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+
+	names := make([]string, len(s.scopes))
+	for i, sc := range s.scopes {
+		names[i] = string(sc)
+	}
+	str := strings.Join(names, ",")
+
+	if s.credential {
+		key := make([]byte, 32) //  32 bytes for AES-256
+		copy(key[:], s.key)
+		enc, err := AESECBEncrypt([]byte(passwd), key)
+		if err != nil {
+			return "", fmt.Errorf("encode credential failed: %v", err)
+		}
+		str = fmt.Sprintf("%s|%s", str, base64.StdEncoding.EncodeToString(enc))
+	}
+
+	return str, nil
+}
+
+// scopeNames returns the scope list of a scopes string without the credential part
+func scopeNames(str string) string {
+	return strings.SplitN(str, "|", 2)[0]
+}
+
+// hasCredential returns true if the scopes string has an encoded credential part
+func hasCredential(str string) bool {
+	return strings.Contains(str, "|")
+}
+
+// parseCredentialScopes returns the Scopes of a string encoded with the default key and passwd,
+// e.g. from GetCSIScopes, so that its credential part can follow a rotated password.
+// It returns nil if the string was built in another way.
+func parseCredentialScopes(str, passwd string) *Scopes {
+	if !hasCredential(str) {
+		return nil
+	}
+
+	scopes := []Scope{}
+	for _, name := range strings.Split(scopeNames(str), ",") {
+		scopes = append(scopes, Scope(name))
+	}
+	scopeSet := NewScopes(scopes...).WithCredential(nil)
+	if encoded, err := scopeSet.Encode(passwd); err != nil || encoded != str {
+		return nil
+	}
+
+	return scopeSet
+}
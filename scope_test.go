@@ -0,0 +1,109 @@
+package goqsan
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestScopesEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes *Scopes
+		passwd string
+		want   string
+		fail   bool
+	}{
+		{"single", NewScopes(ScopeReadOnly), "1234", "readonly", false},
+		{"multiple", NewScopes(ScopeReadWrite, ScopeAdmin), "1234", "readwrite,admin", false},
+		{"csi with credential", NewScopes(ScopeCSIReadWrite).WithCredential(nil), "1234", "csi.readwrite|sV2YoKMUhA8ysrY4FL2f2Q==", false},
+		{"custom key", NewScopes(ScopeCSIReadOnly).WithCredential([]byte("qsanscope1234")), "1234", "csi.readonly|sV2YoKMUhA8ysrY4FL2f2Q==", false},
+		{"empty", NewScopes(), "1234", "", true},
+		{"unsupported", NewScopes("superuser"), "1234", "", true},
+		{"long key", NewScopes(ScopeReadOnly).WithCredential(make([]byte, 33)), "1234", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.scopes.Encode(tt.passwd)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s: Encode should fail, returned %q", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Encode returned %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestGetCSIScopes(t *testing.T) {
+	// Values returned by GetCSIScopes before the Scopes type was added
+	legacy := map[string]string{
+		"1234":                  "csi.readwrite|sV2YoKMUhA8ysrY4FL2f2Q==",
+		"P@ssw0rd-Rotated!2024": "csi.readwrite|CSPy1Y+dGxi8HQtww7dn6zS3sftzIQBXT5Ep2/Mczzc=",
+	}
+
+	for passwd, want := range legacy {
+		if got := GetCSIScopes(passwd); got != want {
+			t.Errorf("GetCSIScopes(%q) = %q, want %q", passwd, got, want)
+		}
+		if parseCredentialScopes(want, passwd) == nil {
+			t.Errorf("parseCredentialScopes(%q) should return the scopes", want)
+		}
+	}
+
+	if parseCredentialScopes(GetCSIScopes("1234"), "5678") != nil {
+		t.Errorf("parseCredentialScopes with another password should return nil")
+	}
+	if parseCredentialScopes("csi.readwrite", "1234") != nil {
+		t.Errorf("parseCredentialScopes without credential should return nil")
+	}
+}
+
+func TestScopesReadOnly(t *testing.T) {
+	tests := []struct {
+		scopes *Scopes
+		want   bool
+	}{
+		{NewScopes(), false},
+		{NewScopes(ScopeReadOnly), true},
+		{NewScopes(ScopeReadOnly, ScopeCSIReadOnly), true},
+		{NewScopes(ScopeReadOnly, ScopeReadWrite), false},
+		{NewScopes(ScopeCSIReadWrite), false},
+		{NewScopes(ScopeAdmin), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.scopes.ReadOnly(); got != tt.want {
+			t.Errorf("%v: ReadOnly() = %t, want %t", tt.scopes.scopes, got, tt.want)
+		}
+	}
+}
+
+func TestIsMutatingRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/rest/v2/storage/pools", false},
+		{http.MethodHead, "/rest/v2/storage/pools", false},
+		{http.MethodOptions, "/rest/v2/storage/pools", false},
+		{http.MethodPost, "/rest/v2/storage/block/volumes", true},
+		{http.MethodPatch, "/rest/v2/storage/block/volumes/1", true},
+		{http.MethodPut, "/rest/v2/system/maintenance", true},
+		{http.MethodDelete, "/rest/v2/storage/block/volumes/1", true},
+		{http.MethodPost, "/auth/refresh", false},
+		{http.MethodPost, "/auth/revoke", false},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, "http://10.0.0.1"+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isMutatingRequest(req); got != tt.want {
+			t.Errorf("%s %s: isMutatingRequest = %t, want %t", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
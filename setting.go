@@ -0,0 +1,227 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrClockSkew is returned by CheckClockSkew if the storage clock drifts too much
+var ErrClockSkew = errors.New("clock skew exceeds the limit")
+
+// GET /rest/v2/system/name
+// PATCH /rest/v2/system/name
+type SystemNameData struct {
+	SystemName string `json:"systemName"`
+}
+
+// return value of GET /rest/v2/system/dateTime
+type DateTimeData struct {
+	Time     int64  `json:"time"` // unix time in seconds
+	TimeZone string `json:"timeZone"`
+}
+
+// PATCH /rest/v2/system/dateTime
+type DateTimeParam struct {
+	Time     int64  `json:"time,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// GET /rest/v2/system/ntp
+// PATCH /rest/v2/system/ntp
+type NTPData struct {
+	Enable  bool     `json:"enable"`
+	Servers []string `json:"servers"`
+}
+
+// GET /rest/v2/system/dns
+// PATCH /rest/v2/system/dns
+type DNSData struct {
+	Servers []string `json:"servers"`
+}
+
+// GetSystemName get the system name
+func (s *SystemOp) GetSystemName(ctx context.Context) (string, error) {
+	client, err := s.auth()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/name", nil)
+	if err != nil {
+		return "", err
+	}
+
+	res := SystemNameData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return "", err
+	}
+	return res.SystemName, nil
+}
+
+// SetSystemName change the system name
+func (s *SystemOp) SetSystemName(ctx context.Context, name string) (string, error) {
+	client, err := s.auth()
+	if err != nil {
+		return "", err
+	}
+
+	rawdata, _ := json.Marshal(SystemNameData{SystemName: name})
+	req, err := client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/name", string(rawdata))
+	if err != nil {
+		return "", err
+	}
+
+	res := SystemNameData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return "", err
+	}
+	return res.SystemName, nil
+}
+
+// GetDateTime get the system date, time and time zone
+func (s *SystemOp) GetDateTime(ctx context.Context) (*DateTimeData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/dateTime", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := DateTimeData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetDateTime change the system date, time or time zone. Zero values are not changed.
+func (s *SystemOp) SetDateTime(ctx context.Context, param *DateTimeParam) (*DateTimeData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/dateTime", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := DateTimeData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetNTP get NTP settings
+func (s *SystemOp) GetNTP(ctx context.Context) (*NTPData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/ntp", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := NTPData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetNTP enable or disable NTP with the given servers
+func (s *SystemOp) SetNTP(ctx context.Context, enable bool, servers []string) (*NTPData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	rawdata, _ := json.Marshal(NTPData{Enable: enable, Servers: servers})
+	req, err := client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/ntp", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := NTPData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetDNS get DNS servers
+func (s *SystemOp) GetDNS(ctx context.Context) (*DNSData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/dns", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := DNSData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetDNS change DNS servers
+func (s *SystemOp) SetDNS(ctx context.Context, servers []string) (*DNSData, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	rawdata, _ := json.Marshal(DNSData{Servers: servers})
+	req, err := client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/dns", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := DNSData{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CheckClockSkew returns the storage time minus local time.
+// If the absolute skew is greater than maxSkew, ErrClockSkew is also returned.
+func (s *SystemOp) CheckClockSkew(ctx context.Context, maxSkew time.Duration) (time.Duration, error) {
+	before := time.Now()
+	dt, err := s.GetDateTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	after := time.Now()
+
+	// Compare with the middle of the round trip
+	local := before.Add(after.Sub(before) / 2)
+	skew := time.Unix(dt.Time, 0).Sub(local).Truncate(time.Second)
+
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs > maxSkew {
+		return skew, fmt.Errorf("%w: storage clock differs by %v (limit %v)", ErrClockSkew, skew, maxSkew)
+	}
+
+	return skew, nil
+}
@@ -0,0 +1,167 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// SpareType is the scope of a spare disk
+type SpareType string
+
+const (
+	// SpareGlobal replaces a failed disk of any pool
+	SpareGlobal SpareType = "GLOBAL"
+	// SpareLocal replaces a failed disk of its pool
+	SpareLocal SpareType = "LOCAL"
+	// SpareDedicated replaces a failed disk of one disk group of its pool
+	SpareDedicated SpareType = "DEDICATED"
+)
+
+// return value of GET /rest/v2/storage/spares
+type SpareData struct {
+	DiskID      string    `json:"diskId"`
+	Type        SpareType `json:"type"`
+	PoolID      string    `json:"poolId,omitempty"`
+	DiskGroupID string    `json:"diskGroupId,omitempty"`
+}
+
+// SpareCoverage reports whether the disks of one media type in a pool are covered by a spare
+type SpareCoverage struct {
+	PoolID    string
+	MediaType DiskMediaType
+	// MinCapacity is the capacity of the largest disk of the media type in the pool
+	MinCapacity uint64
+	// Spares are the IDs of the spare disks which can replace the disks
+	Spares  []string
+	Covered bool
+}
+
+// ListSpares list all spare disks
+func (v *DiskOp) ListSpares(ctx context.Context) (*[]SpareData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/spares", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SpareData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// AssignSpare assigns a free disk as a spare. PoolID is required by local and dedicated spares,
+// and DiskGroupID by dedicated spares.
+// POST /rest/v2/storage/spares
+func (v *DiskOp) AssignSpare(ctx context.Context, param *SpareData) (*SpareData, error) {
+	switch param.Type {
+	case SpareGlobal:
+	case SpareLocal:
+		if param.PoolID == "" {
+			return nil, fmt.Errorf("local spare needs a pool")
+		}
+	case SpareDedicated:
+		if param.PoolID == "" || param.DiskGroupID == "" {
+			return nil, fmt.Errorf("dedicated spare needs a pool and a disk group")
+		}
+	default:
+		return nil, fmt.Errorf("unknown spare type %s", param.Type)
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/storage/spares", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := SpareData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RemoveSpare turns a spare disk back to a free disk
+// DELETE /rest/v2/storage/spares/_diskId
+func (v *DiskOp) RemoveSpare(ctx context.Context, diskId string) error {
+
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, "/rest/v2/storage/spares/"+diskId, nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SpareCoverage reports, for every pool and media type of its disks, whether a spare of the same
+// media type and enough capacity can replace them. Dedicated spares only cover one disk group,
+// so they are not counted.
+func (v *DiskOp) SpareCoverage(ctx context.Context) ([]SpareCoverage, error) {
+	disks, err := v.ListDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spares, err := v.ListSpares(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return spareCoverage(*disks, *spares), nil
+}
+
+func spareCoverage(disks []DiskData, spares []SpareData) []SpareCoverage {
+	diskMap := map[string]*DiskData{}
+	for i := range disks {
+		diskMap[disks[i].ID] = &disks[i]
+	}
+
+	type key struct {
+		poolId    string
+		mediaType DiskMediaType
+	}
+	required := map[key]uint64{}
+	for _, d := range disks {
+		if d.Usage != DiskUsagePool || d.PoolID == "" {
+			continue
+		}
+		k := key{d.PoolID, d.MediaType}
+		if d.Capacity > required[k] {
+			required[k] = d.Capacity
+		}
+	}
+
+	res := []SpareCoverage{}
+	for k, capacity := range required {
+		cov := SpareCoverage{PoolID: k.poolId, MediaType: k.mediaType, MinCapacity: capacity, Spares: []string{}}
+		for _, s := range spares {
+			if s.Type == SpareDedicated || (s.Type == SpareLocal && s.PoolID != k.poolId) {
+				continue
+			}
+			d, ok := diskMap[s.DiskID]
+			if !ok || d.MediaType != k.mediaType || d.Capacity < capacity || d.Health == HealthFailed {
+				continue
+			}
+			cov.Spares = append(cov.Spares, s.DiskID)
+		}
+		cov.Covered = len(cov.Spares) > 0
+		res = append(res, cov)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].PoolID != res[j].PoolID {
+			return res[i].PoolID < res[j].PoolID
+		}
+		return res[i].MediaType < res[j].MediaType
+	})
+	return res
+}
@@ -0,0 +1,305 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrSSDInUse matches every SSDInUseError by errors.Is
+var ErrSSDInUse = errors.New("SSD is already in use")
+
+// SSDCacheOp handles SSD cache related methods of the QSAN storage.
+type SSDCacheOp struct {
+	client *AuthClient
+}
+
+// SSDCacheMode is the mode of an SSD cache pool
+type SSDCacheMode string
+
+const (
+	SSDCacheReadOnly  SSDCacheMode = "READ_ONLY"
+	SSDCacheReadWrite SSDCacheMode = "READ_WRITE"
+)
+
+// SSDCacheIOType is the I/O profile of a cached volume
+type SSDCacheIOType string
+
+const (
+	IOTypeDatabase   SSDCacheIOType = "DATABASE"
+	IOTypeFileSystem SSDCacheIOType = "FILE_SYSTEM"
+	IOTypeWebService SSDCacheIOType = "WEB_SERVICE"
+	IOTypeCustom     SSDCacheIOType = "CUSTOMIZATION"
+)
+
+// Error codes of the storage rejecting an SSD which is not free
+var ssdInUseCodes = map[int]DiskUsage{
+	10601: DiskUsagePool,
+	10602: DiskUsageSpare,
+	10603: DiskUsageCache,
+}
+
+// SSDInUseError is returned when an SSD of a new cache pool is not free.
+// Err is set if the storage rejected the SSD, DiskID is empty if it was not reported.
+type SSDInUseError struct {
+	DiskID string
+	Usage  DiskUsage
+	PoolID string
+	Err    *RestError
+}
+
+func (e *SSDInUseError) Error() string {
+	if e.DiskID == "" {
+		return fmt.Sprintf("%s: an SSD is used as %s: %v", ErrSSDInUse, e.Usage, e.Err)
+	}
+	if e.PoolID != "" {
+		return fmt.Sprintf("%s: disk %s is used as %s of pool %s", ErrSSDInUse, e.DiskID, e.Usage, e.PoolID)
+	}
+	return fmt.Sprintf("%s: disk %s is used as %s", ErrSSDInUse, e.DiskID, e.Usage)
+}
+
+func (e *SSDInUseError) Is(target error) bool {
+	return target == ErrSSDInUse
+}
+
+func (e *SSDInUseError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// Convert a RestError of an SSD in use to SSDInUseError.
+// The disk is known if only one SSD is given.
+func ssdCacheError(err error, diskIds []string) error {
+	resterr, ok := err.(*RestError)
+	if !ok {
+		return err
+	}
+	if usage, ok := ssdInUseCodes[resterr.ErrResp.Error.Code]; ok {
+		inUse := &SSDInUseError{Usage: usage, Err: resterr}
+		if len(diskIds) == 1 {
+			inUse.DiskID = diskIds[0]
+		}
+		return inUse
+	}
+	return err
+}
+
+// return value of GET /rest/v2/storage/ssdCaches
+type SSDCacheData struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Mode         SSDCacheMode `json:"mode"`
+	DiskIDs      []string     `json:"diskIds"`
+	TotalSize    uint64       `json:"totalSize"`
+	UsedSize     uint64       `json:"usedSize"`
+	Health       HealthStatus `json:"health"`
+	State        string       `json:"state"`
+	NumOfVolumes int          `json:"numOfVolumes"`
+}
+
+// POST /rest/v2/storage/ssdCaches
+type SSDCacheCreateParam struct {
+	Name    string       `json:"name"`
+	Mode    SSDCacheMode `json:"mode"`
+	DiskIDs []string     `json:"diskIds"`
+}
+
+// return value of GET /rest/v2/storage/ssdCaches/_cacheId/volumes
+type SSDCacheVolumeData struct {
+	VolumeID string         `json:"volumeId"`
+	IOType   SSDCacheIOType `json:"ioType"`
+	UsedSize uint64         `json:"usedSize"`
+}
+
+// SSDCacheHits are hit counters of an SSD cache pool or a cached volume
+type SSDCacheHits struct {
+	ReadHits    uint64 `json:"readHits"`
+	ReadMisses  uint64 `json:"readMisses"`
+	WriteHits   uint64 `json:"writeHits"`
+	WriteMisses uint64 `json:"writeMisses"`
+}
+
+// return value of GET /rest/v2/storage/ssdCaches/_cacheId/stats
+type SSDCacheStats struct {
+	CacheID string `json:"cacheId"`
+	SSDCacheHits
+	Volumes []SSDCacheVolumeStats `json:"volumes"`
+}
+
+type SSDCacheVolumeStats struct {
+	VolumeID string `json:"volumeId"`
+	SSDCacheHits
+}
+
+// ReadHitRate returns the read hit rate in percent
+func (h *SSDCacheHits) ReadHitRate() float64 {
+	return hitRate(h.ReadHits, h.ReadMisses)
+}
+
+// WriteHitRate returns the write hit rate in percent
+func (h *SSDCacheHits) WriteHitRate() float64 {
+	return hitRate(h.WriteHits, h.WriteMisses)
+}
+
+func hitRate(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) * 100 / float64(hits+misses)
+}
+
+// NewSSDCache returns SSD cache operation
+func NewSSDCache(client *AuthClient) *SSDCacheOp {
+	return &SSDCacheOp{client}
+}
+
+// ListSSDCaches list all SSD cache pools
+func (v *SSDCacheOp) ListSSDCaches(ctx context.Context) (*[]SSDCacheData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/ssdCaches", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SSDCacheData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListSSDCacheByID list an SSD cache pool with cacheId
+func (v *SSDCacheOp) ListSSDCacheByID(ctx context.Context, cacheId string) (*SSDCacheData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/ssdCaches/"+cacheId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := SSDCacheData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CreateSSDCache create an SSD cache pool. It fails with SSDInUseError if any disk is not free.
+func (v *SSDCacheOp) CreateSSDCache(ctx context.Context, param *SSDCacheCreateParam) (*SSDCacheData, error) {
+	if len(param.DiskIDs) == 0 {
+		return nil, errors.New("no SSD is given")
+	}
+
+	diskOp := NewDisk(v.client)
+	for _, id := range param.DiskIDs {
+		disk, err := diskOp.ListDiskByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if disk.MediaType != MediaSSD && disk.MediaType != MediaNVMe {
+			return nil, fmt.Errorf("disk %s is %s, not SSD", id, disk.MediaType)
+		}
+		if disk.Usage != DiskUsageFree {
+			return nil, &SSDInUseError{DiskID: id, Usage: disk.Usage, PoolID: disk.PoolID}
+		}
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/storage/ssdCaches", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	// A disk may be taken between the check above and the request
+	res := SSDCacheData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, ssdCacheError(err, param.DiskIDs)
+	}
+	return &res, nil
+}
+
+// DeleteSSDCache delete an SSD cache pool
+func (v *SSDCacheOp) DeleteSSDCache(ctx context.Context, cacheId string) error {
+
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, "/rest/v2/storage/ssdCaches/"+cacheId, nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListCachedVolumes list volumes cached by an SSD cache pool
+func (v *SSDCacheOp) ListCachedVolumes(ctx context.Context, cacheId string) (*[]SSDCacheVolumeData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/ssdCaches/"+cacheId+"/volumes", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SSDCacheVolumeData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// EnableVolumeCache enables SSD caching of a volume with an I/O profile
+// PUT /rest/v2/storage/ssdCaches/_cacheId/volumes/_volumeId
+func (v *SSDCacheOp) EnableVolumeCache(ctx context.Context, cacheId, volId string, ioType SSDCacheIOType) (*SSDCacheVolumeData, error) {
+
+	param := struct {
+		IOType SSDCacheIOType `json:"ioType"`
+	}{ioType}
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPut, "/rest/v2/storage/ssdCaches/"+cacheId+"/volumes/"+volId, string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := SSDCacheVolumeData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DisableVolumeCache disables SSD caching of a volume
+// DELETE /rest/v2/storage/ssdCaches/_cacheId/volumes/_volumeId
+func (v *SSDCacheOp) DisableVolumeCache(ctx context.Context, cacheId, volId string) error {
+
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, "/rest/v2/storage/ssdCaches/"+cacheId+"/volumes/"+volId, nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetStats get the hit statistics of an SSD cache pool and its volumes
+func (v *SSDCacheOp) GetStats(ctx context.Context, cacheId string) (*SSDCacheStats, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/ssdCaches/"+cacheId+"/stats", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := SSDCacheStats{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
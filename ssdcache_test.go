@@ -0,0 +1,93 @@
+package goqsan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSSDCache(t *testing.T) {
+	fmt.Println("------------TestSSDCache--------------")
+	ctx = context.Background()
+
+	listSSDCacheTest(t)
+	ssdInUseTest(t)
+}
+
+func listSSDCacheTest(t *testing.T) {
+	fmt.Println("listSSDCacheTest Enter")
+
+	caches, err := testConf.ssdCacheOp.ListSSDCaches(ctx)
+	if err != nil {
+		t.Fatalf("ListSSDCaches failed: %v", err)
+	}
+	fmt.Printf("  ListSSDCaches cnt: %d\n", len(*caches))
+
+	for _, c := range *caches {
+		vols, err := testConf.ssdCacheOp.ListCachedVolumes(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("ListCachedVolumes(%s) failed: %v", c.ID, err)
+		}
+		stats, err := testConf.ssdCacheOp.GetStats(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("GetStats(%s) failed: %v", c.ID, err)
+		}
+		fmt.Printf("  Cache %s: %d volumes, read hit %.1f%%, write hit %.1f%%\n", c.ID, len(*vols), stats.ReadHitRate(), stats.WriteHitRate())
+	}
+
+	fmt.Println("listSSDCacheTest Leave")
+}
+
+func ssdInUseTest(t *testing.T) {
+	fmt.Println("ssdInUseTest Enter")
+
+	disks, err := testConf.diskOp.ListDisks(ctx)
+	if err != nil {
+		t.Fatalf("ListDisks failed: %v", err)
+	}
+	for _, d := range *disks {
+		if d.MediaType != MediaSSD || d.Usage == DiskUsageFree {
+			continue
+		}
+		_, err := testConf.ssdCacheOp.CreateSSDCache(ctx, &SSDCacheCreateParam{Name: "goqsan-inuse", Mode: SSDCacheReadOnly, DiskIDs: []string{d.ID}})
+		if !errors.Is(err, ErrSSDInUse) {
+			t.Fatalf("CreateSSDCache with disk %s used as %s should fail with ErrSSDInUse: %v", d.ID, d.Usage, err)
+		}
+		break
+	}
+
+	fmt.Println("ssdInUseTest Leave")
+}
+
+func TestSSDCacheError(t *testing.T) {
+	restErr := func(code int) *RestError {
+		e := &RestError{ReqMethod: "POST", ReqUrl: "/rest/v2/storage/ssdCaches", StatusCode: 400}
+		e.ErrResp.Error.Code = code
+		return e
+	}
+
+	err := ssdCacheError(restErr(10601), []string{"5"})
+	var inUse *SSDInUseError
+	if !errors.Is(err, ErrSSDInUse) || !errors.As(err, &inUse) {
+		t.Fatalf("ssdCacheError returned %v, expected SSDInUseError", err)
+	}
+	if inUse.DiskID != "5" || inUse.Usage != DiskUsagePool {
+		t.Fatalf("ssdCacheError returned %+v", inUse)
+	}
+	var rest *RestError
+	if !errors.As(err, &rest) || rest.ErrResp.Error.Code != 10601 {
+		t.Fatalf("SSDInUseError does not unwrap to the RestError: %v", err)
+	}
+
+	if err := ssdCacheError(restErr(10603), []string{"5", "6"}); !errors.As(err, &inUse) || inUse.DiskID != "" || inUse.Usage != DiskUsageCache {
+		t.Fatalf("ssdCacheError with two disks returned %v", err)
+	}
+	if err := ssdCacheError(restErr(10001), nil); errors.Is(err, ErrSSDInUse) {
+		t.Fatalf("ssdCacheError of another code returned %v", err)
+	}
+	other := errors.New("timeout")
+	if err := ssdCacheError(other, nil); err != other {
+		t.Fatalf("ssdCacheError of a non-RestError returned %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// StatsOp handles performance statistics related methods of the QSAN storage.
+type StatsOp struct {
+	client *AuthClient
+}
+
+// StatsKind is the kind of object which statistics are collected for
+type StatsKind string
+
+const (
+	StatsVolume    StatsKind = "volumes"
+	StatsPool      StatsKind = "pools"
+	StatsFCPort    StatsKind = "fibreChannel"
+	StatsIscsiPort StatsKind = "iscsi"
+)
+
+// return value of GET /rest/v2/statistics/_kind
+// Counters are accumulated since the object was created or the controller started.
+type StatsCounter struct {
+	ID           string `json:"id"`
+	Timestamp    int64  `json:"timestamp"` // milliseconds
+	ReadIOs      uint64 `json:"readIos"`
+	WriteIOs     uint64 `json:"writeIos"`
+	ReadBytes    uint64 `json:"readBytes"`
+	WriteBytes   uint64 `json:"writeBytes"`
+	ReadLatency  uint64 `json:"readLatency"`  // total microseconds
+	WriteLatency uint64 `json:"writeLatency"` // total microseconds
+}
+
+// StatsRate is the rate between two samples of StatsCounter
+type StatsRate struct {
+	ID              string
+	Time            time.Time
+	Interval        time.Duration
+	ReadIops        float64
+	WriteIops       float64
+	ReadThroughput  float64 // bytes per second
+	WriteThroughput float64 // bytes per second
+	ReadLatency     time.Duration
+	WriteLatency    time.Duration
+}
+
+// NewStats returns statistics operation
+func NewStats(client *AuthClient) *StatsOp {
+	return &StatsOp{client}
+}
+
+// ListStats list counters of all objects of the kind
+func (v *StatsOp) ListStats(ctx context.Context, kind StatsKind) (*[]StatsCounter, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/statistics/"+string(kind), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []StatsCounter{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetStats get counters of certain object
+func (v *StatsOp) GetStats(ctx context.Context, kind StatsKind, id string) (*StatsCounter, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/statistics/"+string(kind)+"/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := StatsCounter{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ComputeRate returns the rate between prev and cur samples of the same object
+func ComputeRate(prev, cur *StatsCounter) (*StatsRate, error) {
+	if prev.ID != cur.ID {
+		return nil, fmt.Errorf("samples of different objects (%s vs %s)", prev.ID, cur.ID)
+	}
+
+	interval := time.Duration(cur.Timestamp-prev.Timestamp) * time.Millisecond
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid sample interval %v of %s", interval, cur.ID)
+	}
+	if cur.ReadIOs < prev.ReadIOs || cur.WriteIOs < prev.WriteIOs || cur.ReadBytes < prev.ReadBytes || cur.WriteBytes < prev.WriteBytes {
+		return nil, fmt.Errorf("counters of %s were reset", cur.ID)
+	}
+
+	sec := interval.Seconds()
+	readIOs := cur.ReadIOs - prev.ReadIOs
+	writeIOs := cur.WriteIOs - prev.WriteIOs
+	rate := &StatsRate{
+		ID:              cur.ID,
+		Time:            time.Unix(0, cur.Timestamp*int64(time.Millisecond)),
+		Interval:        interval,
+		ReadIops:        float64(readIOs) / sec,
+		WriteIops:       float64(writeIOs) / sec,
+		ReadThroughput:  float64(cur.ReadBytes-prev.ReadBytes) / sec,
+		WriteThroughput: float64(cur.WriteBytes-prev.WriteBytes) / sec,
+	}
+	if readIOs > 0 && cur.ReadLatency >= prev.ReadLatency {
+		rate.ReadLatency = time.Duration((cur.ReadLatency-prev.ReadLatency)/readIOs) * time.Microsecond
+	}
+	if writeIOs > 0 && cur.WriteLatency >= prev.WriteLatency {
+		rate.WriteLatency = time.Duration((cur.WriteLatency-prev.WriteLatency)/writeIOs) * time.Microsecond
+	}
+
+	return rate, nil
+}
+
+// Default sampling interval of StatsSampler
+const defaultStatsInterval = 5 * time.Second
+
+// StatsSampler collects rates of an object every interval into a bounded ring buffer
+type StatsSampler struct {
+	op       *StatsOp
+	kind     StatsKind
+	id       string
+	interval time.Duration
+
+	mu    sync.Mutex
+	buf   []StatsRate
+	start int
+	count int
+}
+
+// NewStatsSampler returns a sampler keeping the latest size rates. A non-positive interval uses 5 seconds.
+func NewStatsSampler(op *StatsOp, kind StatsKind, id string, interval time.Duration, size int) *StatsSampler {
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	return &StatsSampler{
+		op:       op,
+		kind:     kind,
+		id:       id,
+		interval: interval,
+		buf:      make([]StatsRate, size),
+	}
+}
+
+// Run samples until ctx is done. Failed samples are logged and skipped.
+func (s *StatsSampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	var prev *StatsCounter
+	for {
+		cur, err := s.op.GetStats(ctx, s.kind, s.id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			glog.Warningf("[StatsSampler] GetStats(%s, %s) failed: %v\n", s.kind, s.id, err)
+		} else {
+			if prev != nil {
+				rate, err := ComputeRate(prev, cur)
+				if err != nil {
+					glog.Warningf("[StatsSampler] %v\n", err)
+				} else {
+					s.add(*rate)
+				}
+			}
+			prev = cur
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *StatsSampler) add(rate StatsRate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count < len(s.buf) {
+		s.buf[(s.start+s.count)%len(s.buf)] = rate
+		s.count++
+		return
+	}
+	// Overwrite the oldest one
+	s.buf[s.start] = rate
+	s.start = (s.start + 1) % len(s.buf)
+}
+
+// Samples returns the collected rates from oldest to newest
+func (s *StatsSampler) Samples() []StatsRate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]StatsRate, s.count)
+	for i := 0; i < s.count; i++ {
+		res[i] = s.buf[(s.start+i)%len(s.buf)]
+	}
+	return res
+}
+
+// Latest returns the newest rate, false if there is none yet
+func (s *StatsSampler) Latest() (StatsRate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return StatsRate{}, false
+	}
+	return s.buf[(s.start+s.count-1)%len(s.buf)], true
+}
@@ -0,0 +1,120 @@
+package goqsan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	fmt.Println("------------TestStats--------------")
+	ctx = context.Background()
+
+	listStatsTest(t)
+	samplerTest(t)
+}
+
+func listStatsTest(t *testing.T) {
+	fmt.Println("listStatsTest Enter")
+
+	for _, kind := range []StatsKind{StatsVolume, StatsPool, StatsFCPort, StatsIscsiPort} {
+		counters, err := testConf.statsOp.ListStats(ctx, kind)
+		if err != nil {
+			t.Fatalf("ListStats(%s) failed: %v", kind, err)
+		}
+		fmt.Printf("  ListStats(%s) cnt: %d\n", kind, len(*counters))
+	}
+
+	fmt.Println("listStatsTest Leave")
+}
+
+func samplerTest(t *testing.T) {
+	fmt.Println("samplerTest Enter")
+
+	sampleCtx, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	sampler := NewStatsSampler(testConf.statsOp, StatsPool, testConf.poolId, 2*time.Second, 2)
+	sampler.Run(sampleCtx)
+
+	samples := sampler.Samples()
+	if len(samples) != 2 {
+		t.Fatalf("Sampler collected %d samples, expected 2", len(samples))
+	}
+	for _, s := range samples {
+		fmt.Printf("  Sample: %+v\n", s)
+	}
+
+	fmt.Println("samplerTest Leave")
+}
+
+func TestComputeRate(t *testing.T) {
+	fmt.Println("------------TestComputeRate--------------")
+
+	prev := &StatsCounter{ID: "1", Timestamp: 10000, ReadIOs: 100, WriteIOs: 50, ReadBytes: 4096, WriteBytes: 8192, ReadLatency: 1000, WriteLatency: 500}
+	cur := &StatsCounter{ID: "1", Timestamp: 12000, ReadIOs: 300, WriteIOs: 50, ReadBytes: 4096 + 2*1048576, WriteBytes: 8192, ReadLatency: 1000 + 200*250, WriteLatency: 500}
+
+	rate, err := ComputeRate(prev, cur)
+	if err != nil {
+		t.Fatalf("ComputeRate failed: %v", err)
+	}
+	if rate.Interval != 2*time.Second || !rate.Time.Equal(time.Unix(12, 0)) {
+		t.Fatalf("ComputeRate returned interval %v at %v", rate.Interval, rate.Time)
+	}
+	if rate.ReadIops != 100 || rate.WriteIops != 0 {
+		t.Fatalf("ComputeRate returned iops %v/%v, expected 100/0", rate.ReadIops, rate.WriteIops)
+	}
+	if rate.ReadThroughput != 1048576 || rate.WriteThroughput != 0 {
+		t.Fatalf("ComputeRate returned throughput %v/%v, expected 1048576/0", rate.ReadThroughput, rate.WriteThroughput)
+	}
+	if rate.ReadLatency != 250*time.Microsecond || rate.WriteLatency != 0 {
+		t.Fatalf("ComputeRate returned latency %v/%v, expected 250µs/0", rate.ReadLatency, rate.WriteLatency)
+	}
+
+	reset := *cur
+	reset.ReadIOs = 10
+	if _, err := ComputeRate(prev, &reset); err == nil {
+		t.Fatalf("ComputeRate with reset counters should fail")
+	}
+
+	same := *cur
+	same.Timestamp = prev.Timestamp
+	if _, err := ComputeRate(prev, &same); err == nil {
+		t.Fatalf("ComputeRate with zero interval should fail")
+	}
+
+	other := *cur
+	other.ID = "2"
+	if _, err := ComputeRate(prev, &other); err == nil {
+		t.Fatalf("ComputeRate of different objects should fail")
+	}
+}
+
+func TestStatsSamplerBuffer(t *testing.T) {
+	fmt.Println("------------TestStatsSamplerBuffer--------------")
+
+	sampler := NewStatsSampler(nil, StatsPool, "1", 0, 3)
+	if sampler.interval != defaultStatsInterval {
+		t.Fatalf("NewStatsSampler interval is %v, expected %v", sampler.interval, defaultStatsInterval)
+	}
+	if _, ok := sampler.Latest(); ok {
+		t.Fatalf("Latest of an empty sampler should return false")
+	}
+
+	for i := 1; i <= 5; i++ {
+		sampler.add(StatsRate{ID: "1", ReadIops: float64(i)})
+	}
+	samples := sampler.Samples()
+	if len(samples) != 3 {
+		t.Fatalf("Sampler kept %d samples, expected 3", len(samples))
+	}
+	for i, s := range samples {
+		if s.ReadIops != float64(i+3) {
+			t.Fatalf("Samples()[%d] is %v, expected %d", i, s.ReadIops, i+3)
+		}
+	}
+	if latest, ok := sampler.Latest(); !ok || latest.ReadIops != 5 {
+		t.Fatalf("Latest returned %v, %t, expected 5", latest.ReadIops, ok)
+	}
+}
@@ -0,0 +1,111 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// The header of the SHA-256 checksum of downloaded data
+const checksumHeader = "X-Checksum-Sha256"
+
+// ErrChecksumMismatch is returned when downloaded data is corrupted
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ProgressFunc reports transferred bytes. total is -1 if unknown.
+type ProgressFunc func(transferred, total int64)
+
+type progressReader struct {
+	r           io.Reader
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func newProgressReader(r io.Reader, total int64, progress ProgressFunc) io.Reader {
+	if progress == nil {
+		return r
+	}
+	return &progressReader{r: r, total: total, progress: progress}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.transferred += int64(n)
+		p.progress(p.transferred, p.total)
+	}
+	return n, err
+}
+
+// verifyReader checks size and checksum of a response body when it reaches EOF
+type verifyReader struct {
+	body     io.ReadCloser
+	r        io.Reader
+	hash     hash.Hash
+	checksum string
+	size     int64
+	read     int64
+}
+
+// newVerifyReader wraps the response body. Without checksum header, only the size is checked.
+func newVerifyReader(res *http.Response, progress ProgressFunc) io.ReadCloser {
+	v := &verifyReader{
+		body:     res.Body,
+		hash:     sha256.New(),
+		checksum: strings.ToLower(res.Header.Get(checksumHeader)),
+		size:     res.ContentLength,
+	}
+	v.r = newProgressReader(io.TeeReader(res.Body, v.hash), res.ContentLength, progress)
+	return v
+}
+
+func (v *verifyReader) Read(b []byte) (int, error) {
+	n, err := v.r.Read(b)
+	v.read += int64(n)
+	if err == io.EOF {
+		if v.size >= 0 && v.read != v.size {
+			return n, fmt.Errorf("%w: received %d of %d bytes", ErrChecksumMismatch, v.read, v.size)
+		}
+		if v.checksum != "" {
+			if sum := hex.EncodeToString(v.hash.Sum(nil)); sum != v.checksum {
+				return n, fmt.Errorf("%w: sha256 %s, expected %s", ErrChecksumMismatch, sum, v.checksum)
+			}
+		}
+	}
+	return n, err
+}
+
+func (v *verifyReader) Close() error {
+	return v.body.Close()
+}
+
+type progressWriter struct {
+	w           io.Writer
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func newProgressWriter(w io.Writer, offset, total int64, progress ProgressFunc) io.Writer {
+	if progress == nil {
+		return w
+	}
+	return &progressWriter{w: w, transferred: offset, total: total, progress: progress}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.transferred += int64(n)
+		p.progress(p.transferred, p.total)
+	}
+	return n, err
+}
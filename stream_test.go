@@ -0,0 +1,61 @@
+package goqsan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestVerifyReader(t *testing.T) {
+	data := "configuration backup"
+	sum := sha256.Sum256([]byte(data))
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		body     string
+		size     int64
+		checksum string
+		fail     bool
+	}{
+		{"valid", data, int64(len(data)), checksum, false},
+		{"upper case checksum", data, int64(len(data)), strings.ToUpper(checksum), false},
+		{"no checksum", data, int64(len(data)), "", false},
+		{"unknown size", data, -1, checksum, false},
+		{"truncated", data[:10], int64(len(data)), "", true},
+		{"longer", data + "!", int64(len(data)), "", true},
+		{"corrupted", strings.Replace(data, "c", "C", 1), int64(len(data)), checksum, true},
+	}
+
+	for _, tt := range tests {
+		res := &http.Response{
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(tt.body)),
+			ContentLength: tt.size,
+		}
+		if tt.checksum != "" {
+			res.Header.Set(checksumHeader, tt.checksum)
+		}
+
+		var transferred int64
+		r := newVerifyReader(res, func(n, total int64) { transferred = n })
+		got, err := io.ReadAll(r)
+		r.Close()
+		if tt.fail {
+			if !errors.Is(err, ErrChecksumMismatch) {
+				t.Errorf("%s: ReadAll returned %v, expected ErrChecksumMismatch", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.body {
+			t.Errorf("%s: ReadAll returned %q, %v", tt.name, got, err)
+		}
+		if transferred != int64(len(tt.body)) {
+			t.Errorf("%s: progress reported %d bytes, expected %d", tt.name, transferred, len(tt.body))
+		}
+	}
+}
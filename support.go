@@ -0,0 +1,170 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// Default polling interval of support bundle generation
+	defaultBundlePollInterval = 5 * time.Second
+	// Max retries to resume an interrupted support bundle download
+	maxBundleDownloadRetries = 5
+)
+
+// SupportBundleState is the state of support bundle generation
+type SupportBundleState string
+
+const (
+	BundleNone       SupportBundleState = "NONE"
+	BundleGenerating SupportBundleState = "GENERATING"
+	BundleReady      SupportBundleState = "READY"
+	BundleFailed     SupportBundleState = "FAILED"
+)
+
+// return value of GET /rest/v2/system/supportBundle
+type SupportBundleStatus struct {
+	State      SupportBundleState `json:"state"`
+	Progress   int                `json:"progress"`
+	Name       string             `json:"name"`
+	Size       int64              `json:"size"`
+	Checksum   string             `json:"checksum"` // SHA-256 in hex
+	CreateTime int64              `json:"createTime"`
+}
+
+// GenerateSupportBundle triggers generation of the support bundle
+// POST /rest/v2/system/supportBundle
+func (s *SystemOp) GenerateSupportBundle(ctx context.Context) (*SupportBundleStatus, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/supportBundle", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := SupportBundleStatus{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetSupportBundleStatus get the state of support bundle generation
+func (s *SystemOp) GetSupportBundleStatus(ctx context.Context) (*SupportBundleStatus, error) {
+	client, err := s.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/supportBundle", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := SupportBundleStatus{}
+	if err := client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DownloadSupportBundle writes the generated bundle from offset to w, and returns the written bytes.
+// A download interrupted by an error can be resumed with offset plus the written bytes.
+// GET /rest/v2/system/supportBundle/download
+func (s *SystemOp) DownloadSupportBundle(ctx context.Context, w io.Writer, offset int64) (int64, error) {
+	client, err := s.auth()
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/supportBundle/download", nil)
+	if err != nil {
+		return 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := client.SendRawRequest(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if offset > 0 && res.StatusCode != http.StatusPartialContent {
+		// Range is not supported, skip the downloaded part
+		glog.V(2).Infof("[DownloadSupportBundle] range is not supported, skip %d bytes\n", offset)
+		if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+			return 0, err
+		}
+	}
+
+	return io.Copy(w, res.Body)
+}
+
+// CollectSupportBundle generates the support bundle, waits for it, and streams it to w.
+// Interrupted downloads are resumed, and the size and checksum are verified at the end.
+func (s *SystemOp) CollectSupportBundle(ctx context.Context, w io.Writer, progress ProgressFunc) (*SupportBundleStatus, error) {
+	status, err := s.GenerateSupportBundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for status.State != BundleReady {
+		if status.State == BundleFailed {
+			return status, fmt.Errorf("generate support bundle failed")
+		}
+		if err := sleepContext(ctx, defaultBundlePollInterval); err != nil {
+			return status, err
+		}
+		if status, err = s.GetSupportBundleStatus(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	total := status.Size
+	if total <= 0 {
+		total = -1
+	}
+	hash := sha256.New()
+	var written int64
+	for retries := 0; ; retries++ {
+		mw := io.MultiWriter(newProgressWriter(w, written, total, progress), hash)
+		n, err := s.DownloadSupportBundle(ctx, mw, written)
+		written += n
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil || retries >= maxBundleDownloadRetries {
+			return status, fmt.Errorf("download support bundle failed at %d bytes: %v", written, err)
+		}
+		glog.Warningf("[CollectSupportBundle] resume download at %d bytes: %v\n", written, err)
+		if err := sleepContext(ctx, time.Second); err != nil {
+			return status, err
+		}
+	}
+
+	if status.Size > 0 && written != status.Size {
+		return status, fmt.Errorf("%w: received %d of %d bytes", ErrChecksumMismatch, written, status.Size)
+	}
+	if status.Checksum != "" {
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != strings.ToLower(status.Checksum) {
+			return status, fmt.Errorf("%w: sha256 %s, expected %s", ErrChecksumMismatch, sum, status.Checksum)
+		}
+	}
+
+	return status, nil
+}
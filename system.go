@@ -4,12 +4,18 @@ package goqsan
 
 import (
 	"context"
+	"errors"
 	"net/http"
 )
 
+// ErrAuthRequired is returned by SystemOp methods which need authentication,
+// if the SystemOp is not created by NewSystemWithAuth
+var ErrAuthRequired = errors.New("authenticated client is required")
+
 // SystemOp handles system related methods of the QSAN storage.
 type SystemOp struct {
-	client *Client
+	client     *Client
+	authClient *AuthClient
 }
 
 // The response data of GetAbout method
@@ -28,7 +34,19 @@ type AboutData struct {
 
 // NewSystem returns system operation
 func NewSystem(client *Client) *SystemOp {
-	return &SystemOp{client}
+	return &SystemOp{client: client}
+}
+
+// NewSystemWithAuth returns system operation which also supports methods with authentication
+func NewSystemWithAuth(client *AuthClient) *SystemOp {
+	return &SystemOp{client: &client.Client, authClient: client}
+}
+
+func (s *SystemOp) auth() (*AuthClient, error) {
+	if s.authClient == nil {
+		return nil, ErrAuthRequired
+	}
+	return s.authClient, nil
 }
 
 // GetAbout get system information without authentication
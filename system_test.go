@@ -1,9 +1,14 @@
 package goqsan
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
+	"time"
 )
 
 var ctx context.Context
@@ -14,6 +19,12 @@ func TestSystem(t *testing.T) {
 	ctx = context.Background()
 
 	getAboutTest(t)
+	getCapabilitiesTest(t)
+	healthSummaryTest(t)
+	settingTest(t)
+	downloadConfigTest(t)
+	clientBundleTest(t)
+	powerTest(t)
 }
 
 func getAboutTest(t *testing.T) {
@@ -26,3 +37,263 @@ func getAboutTest(t *testing.T) {
 
 	fmt.Println("getAboutTest Leave")
 }
+
+func getCapabilitiesTest(t *testing.T) {
+	fmt.Println("getCapabilitiesTest Enter")
+
+	caps, err := testConf.systemOp.GetCapabilities(ctx)
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	fmt.Printf("  FirmwareVer: %s, Features: %+v\n", caps.FirmwareVer, caps.Features)
+
+	fmt.Println("getCapabilitiesTest Leave")
+}
+
+func healthSummaryTest(t *testing.T) {
+	fmt.Println("healthSummaryTest Enter")
+
+	if _, err := testConf.systemOp.HealthSummary(ctx); err != ErrAuthRequired {
+		t.Fatalf("HealthSummary without authentication should fail with ErrAuthRequired: %v", err)
+	}
+
+	ctrls, err := testConf.authSysOp.ListControllers(ctx)
+	if err != nil {
+		t.Fatalf("ListControllers failed: %v", err)
+	}
+	fmt.Printf("  ListControllers cnt: %d\n  %+v\n", len(*ctrls), ctrls)
+
+	sum, err := testConf.authSysOp.HealthSummary(ctx)
+	if err != nil {
+		t.Fatalf("HealthSummary failed: %v", err)
+	}
+	fmt.Printf("  HealthSummary: %+v\n", sum)
+
+	fmt.Println("healthSummaryTest Leave")
+}
+
+func settingTest(t *testing.T) {
+	fmt.Println("settingTest Enter")
+
+	name, err := testConf.authSysOp.GetSystemName(ctx)
+	if err != nil {
+		t.Fatalf("GetSystemName failed: %v", err)
+	}
+	// Set the same name back, so that the system is not changed by the test
+	newName, err := testConf.authSysOp.SetSystemName(ctx, name)
+	if err != nil {
+		t.Fatalf("SetSystemName failed: %v", err)
+	}
+	if newName != name {
+		t.Fatalf("SetSystemName returned %s, expected %s", newName, name)
+	}
+
+	dt, err := testConf.authSysOp.GetDateTime(ctx)
+	if err != nil {
+		t.Fatalf("GetDateTime failed: %v", err)
+	}
+	ntp, err := testConf.authSysOp.GetNTP(ctx)
+	if err != nil {
+		t.Fatalf("GetNTP failed: %v", err)
+	}
+	dns, err := testConf.authSysOp.GetDNS(ctx)
+	if err != nil {
+		t.Fatalf("GetDNS failed: %v", err)
+	}
+	fmt.Printf("  SystemName: %s, DateTime: %+v, NTP: %+v, DNS: %+v\n", name, dt, ntp, dns)
+
+	skew, err := testConf.authSysOp.CheckClockSkew(ctx, 5*time.Minute)
+	fmt.Printf("  Clock skew: %v, err: %v\n", skew, err)
+
+	fmt.Println("settingTest Leave")
+}
+
+func downloadConfigTest(t *testing.T) {
+	fmt.Println("downloadConfigTest Enter")
+
+	var transferred int64
+	r, err := testConf.authSysOp.DownloadConfig(ctx, func(n, total int64) {
+		transferred = n
+	})
+	if err != nil {
+		t.Fatalf("DownloadConfig failed: %v", err)
+	}
+	defer r.Close()
+
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		t.Fatalf("Read config backup failed: %v", err)
+	}
+	if n != transferred {
+		t.Fatalf("Progress reported %d bytes, but %d bytes were read", transferred, n)
+	}
+	fmt.Printf("  Config backup size: %d\n", n)
+
+	fmt.Println("downloadConfigTest Leave")
+}
+
+func clientBundleTest(t *testing.T) {
+	fmt.Println("clientBundleTest Enter")
+
+	var buf bytes.Buffer
+	if err := testConf.authSysOp.WriteClientBundle(ctx, &buf); err != nil {
+		t.Fatalf("WriteClientBundle failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Client bundle is not a zip archive: %v", err)
+	}
+	for _, f := range zr.File {
+		fmt.Printf("  %s (%d bytes)\n", f.Name, f.UncompressedSize64)
+	}
+
+	fmt.Println("clientBundleTest Leave")
+}
+
+func powerTest(t *testing.T) {
+	fmt.Println("powerTest Enter")
+
+	if err := testConf.authSysOp.PreflightCheck(ctx, ""); err != nil && !errors.Is(err, ErrPreflightFailed) {
+		t.Fatalf("PreflightCheck failed: %v", err)
+	}
+
+	// Never reboot the test storage, only make sure a wrong confirmation is refused
+	err := testConf.authSysOp.RebootController(ctx, &PowerOptions{Confirm: "not-a-serial-number"})
+	if err != ErrConfirmationMismatch {
+		t.Fatalf("RebootController should fail with ErrConfirmationMismatch: %v", err)
+	}
+
+	fmt.Println("powerTest Leave")
+}
+
+func TestFirmwareVersion(t *testing.T) {
+	fmt.Println("------------TestFirmwareVersion--------------")
+
+	tests := []struct {
+		a, b string
+		cmp  int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3 (build 0456)", "1.2.3", 0},
+		{"1.2.3.0456", "1.2.3", 1},
+		{"1.2.3-beta", "1.2.3", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"2.0", "1.9.9", 1},
+	}
+	for _, tt := range tests {
+		a, err := ParseFirmwareVersion(tt.a)
+		if err != nil {
+			t.Fatalf("ParseFirmwareVersion(%s) failed: %v", tt.a, err)
+		}
+		b, err := ParseFirmwareVersion(tt.b)
+		if err != nil {
+			t.Fatalf("ParseFirmwareVersion(%s) failed: %v", tt.b, err)
+		}
+		if cmp := a.Compare(b); cmp != tt.cmp {
+			t.Fatalf("Compare(%s, %s) = %d, expected %d", tt.a, tt.b, cmp, tt.cmp)
+		}
+	}
+
+	if _, err := ParseFirmwareVersion("unknown"); err == nil {
+		t.Fatalf("ParseFirmwareVersion(unknown) should fail")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	fmt.Println("------------TestCapabilities--------------")
+
+	tests := []struct {
+		feature Feature
+		older   string
+		min     string
+	}{
+		{FeatureQoS, "1.0.9", "1.1.0"},
+		{FeatureClone, "1.1.9", "1.2.0"},
+		{FeatureSnapshotExpose, "1.1.9", "1.2.0"},
+		{FeatureFCPatch, "1.2.9", "1.3.0"},
+	}
+	if len(tests) != len(DefaultFeatureMinFirmware) {
+		t.Fatalf("%d features are not tested", len(DefaultFeatureMinFirmware)-len(tests))
+	}
+	for _, tt := range tests {
+		if min := DefaultFeatureMinFirmware[tt.feature]; min != tt.min {
+			t.Fatalf("minimum firmware of %s is %s, expected %s", tt.feature, min, tt.min)
+		}
+
+		caps, err := NewCapabilities(&AboutData{FirmwareVer: tt.older})
+		if err != nil {
+			t.Fatalf("NewCapabilities(%s) failed: %v", tt.older, err)
+		}
+		if err := caps.Require(tt.feature); !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("%s on %s: Require returned %v, expected ErrUnsupported", tt.feature, tt.older, err)
+		}
+
+		caps, err = NewCapabilities(&AboutData{FirmwareVer: tt.min})
+		if err != nil {
+			t.Fatalf("NewCapabilities(%s) failed: %v", tt.min, err)
+		}
+		if err := caps.Require(tt.feature); err != nil {
+			t.Fatalf("%s on %s: Require returned %v", tt.feature, tt.min, err)
+		}
+	}
+
+	// A custom matrix replaces the defaults
+	caps, err := NewCapabilitiesWithMatrix(&AboutData{FirmwareVer: "1.0.0"}, map[Feature]string{FeatureQoS: "1.0.0"})
+	if err != nil {
+		t.Fatalf("NewCapabilitiesWithMatrix failed: %v", err)
+	}
+	if !caps.Supports(FeatureQoS) || caps.Supports(FeatureClone) {
+		t.Fatalf("NewCapabilitiesWithMatrix returned %v", caps.Features)
+	}
+	if err := caps.Require(FeatureClone); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Require of a feature not in the matrix returned %v", err)
+	}
+	if _, err := NewCapabilitiesWithMatrix(&AboutData{FirmwareVer: "1.0.0"}, map[Feature]string{FeatureQoS: "latest"}); err == nil {
+		t.Fatalf("NewCapabilitiesWithMatrix with invalid version should fail")
+	}
+
+	var unknown *Capabilities
+	if err := unknown.Require(FeatureFCPatch); err != nil {
+		t.Fatalf("Require of nil capabilities returned %v", err)
+	}
+}
+
+func TestHealthSummary(t *testing.T) {
+	fmt.Println("------------TestHealthSummary--------------")
+
+	sum := &HealthSummary{Status: HealthGood}
+	sum.add("controller", "0", "CTR1", HealthGood)
+	sum.add("fan", "1", "FAN1", HealthGood)
+	if sum.Status != HealthGood || sum.Components != 2 || len(sum.Problems) != 0 {
+		t.Fatalf("healthy summary is %+v", sum)
+	}
+
+	// A pulled power supply is a problem
+	sum.add("powerSupply", "2", "PSU2", HealthAbsent)
+	if sum.Status != HealthAbsent || len(sum.Problems) != 1 || sum.Problems[0].ID != "2" {
+		t.Fatalf("summary with an absent power supply is %+v", sum)
+	}
+
+	// The worst status wins, and every problem is kept
+	sum.add("cacheBackupModule", "3", "CTR1", HealthDegraded)
+	sum.add("temperature", "4", "CPU", HealthWarning)
+	if sum.Status != HealthDegraded || sum.Components != 5 || len(sum.Problems) != 3 {
+		t.Fatalf("summary with problems is %+v", sum)
+	}
+	sum.add("enclosure", "5", "JBOD1", HealthFailed)
+	if sum.Status != HealthFailed || len(sum.Problems) != 4 {
+		t.Fatalf("summary with a failed enclosure is %+v", sum)
+	}
+
+	order := []HealthStatus{HealthGood, HealthAbsent, HealthDegraded, HealthFailed}
+	for i := 1; i < len(order); i++ {
+		if order[i-1].Severity() >= order[i].Severity() {
+			t.Fatalf("%s should be less severe than %s", order[i-1], order[i])
+		}
+	}
+	if HealthAbsent.Severity() != HealthWarning.Severity() {
+		t.Fatalf("ABSENT should be as severe as WARNING")
+	}
+}
@@ -12,7 +12,9 @@ import (
 
 // TargetOp handles target related methods of the QSAN storage.
 type TargetOp struct {
-	client *AuthClient
+	client       *AuthClient
+	caps         *Capabilities
+	validateEths bool
 }
 
 // POST /rest/v2/dataTransfer/targets
@@ -102,9 +104,26 @@ type FCData struct {
 	} `json:"errCounter"`
 }
 
+// PATCH /rest/v2/dataTransfer/protocol/fibreChannel/_fcID
+type FCPatchParam struct {
+	LinkSpeed int    `json:"linkSpeed,omitempty"`
+	Topology  string `json:"topology,omitempty"`
+}
+
 // NewTarget returns volume operation
 func NewTarget(client *AuthClient) *TargetOp {
-	return &TargetOp{client}
+	return &TargetOp{client: client}
+}
+
+// SetCapabilities enables feature checks before requests are sent
+func (v *TargetOp) SetCapabilities(caps *Capabilities) {
+	v.caps = caps
+}
+
+// SetValidateEths enables CreateTarget to check that the eths of iSCSI targets exist and are up.
+// It needs GET /rest/v2/system/network/data, which older firmware does not support.
+func (v *TargetOp) SetValidateEths(enable bool) {
+	v.validateEths = enable
 }
 
 // List all Targets or certain target by target name
@@ -170,6 +189,18 @@ func (v *TargetOp) CreateTarget(ctx context.Context, tgtName, tgtType string, pa
 
 	param.Name = tgtName
 	param.Type = tgtType
+
+	// Check the eths exist and are up before creating the target if enabled by SetValidateEths
+	eths := []string{}
+	for _, iscsi := range param.Iscsis {
+		eths = append(eths, iscsi.Eths...)
+	}
+	if v.validateEths && len(eths) > 0 {
+		if err := NewNetwork(v.client).ValidateEths(ctx, eths); err != nil {
+			return nil, err
+		}
+	}
+
 	rawdata, _ := json.Marshal(param)
 	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/dataTransfer/targets", string(rawdata))
 	if err != nil {
@@ -297,16 +328,22 @@ func (v *TargetOp) ListFC(ctx context.Context) (*[]FCData, error) {
 	return &res, nil
 }
 
-// //patch target fibre channel
-// func (v *TargetOp) ListFC(ctx context.Context, fcID string, param *FCPatchParam) (*[]FCData, error) {
-// 	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/dataTransfer/protocol/fibreChannel"+fcID, nil)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	res := []LunData{}
-// 	if err := v.client.SendRequest(ctx, req, &res); err != nil {
-// 		return nil, err
-// 	}
-// 	return &res, nil
-// }
+// Patch certain fibre channel
+// PATCH /rest/v2/dataTransfer/protocol/fibreChannel/_fcID
+func (v *TargetOp) PatchFC(ctx context.Context, fcID string, param *FCPatchParam) (*FCData, error) {
+	if err := v.caps.Require(FeatureFCPatch); err != nil {
+		return nil, err
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/dataTransfer/protocol/fibreChannel/"+fcID, string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := FCData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -262,3 +262,33 @@ func listPatchFCTest(t *testing.T) {
 
 	// fmt.Println("listPatchFCTest Leave")
 }
+
+func TestCreateTargetValidateEths(t *testing.T) {
+	f := newFakeStorage(t)
+	f.responses["/rest/v2/dataTransfer/targets"] = `{"id":"1","name":"tgt"}`
+	f.responses["/rest/v2/system/network/data"] = `[{"name":"c0e5","linkStatus":"UP"},{"name":"c0e6","linkStatus":"DOWN"}]`
+	ac, err := f.client(t).GetAuthClient(context.Background(), "admin", "1234", "")
+	if err != nil {
+		t.Fatalf("GetAuthClient failed: %v", err)
+	}
+	targetOp := NewTarget(ac)
+	param := func() *CreateTargetParam {
+		return &CreateTargetParam{Iscsis: []Iscsi{{Eths: []string{"c0e5", "c0e6"}}}}
+	}
+
+	// The eths are not checked by default
+	if _, err := targetOp.CreateTarget(context.Background(), "tgt", "iSCSI", param()); err != nil {
+		t.Fatalf("CreateTarget failed: %v", err)
+	}
+	if f.received("GET", "/rest/v2/system/network/data") {
+		t.Fatalf("CreateTarget listed data ports without SetValidateEths")
+	}
+
+	targetOp.SetValidateEths(true)
+	if _, err := targetOp.CreateTarget(context.Background(), "tgt", "iSCSI", param()); err == nil {
+		t.Fatalf("CreateTarget with a link-down eth should fail")
+	}
+	if !f.received("GET", "/rest/v2/system/network/data") {
+		t.Fatalf("CreateTarget did not list data ports with SetValidateEths")
+	}
+}
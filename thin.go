@@ -0,0 +1,143 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// ErrOversubscribed is returned by CreateVolume if a thin volume exceeds the oversubscription policy
+var ErrOversubscribed = errors.New("pool oversubscription ratio exceeded")
+
+// return value of GET /rest/v2/storage/pools/_poolId/thinProvisioning
+// PATCH /rest/v2/storage/pools/_poolId/thinProvisioning
+type ThinProvisioningSetting struct {
+	// Alert thresholds in percentage of used capacity
+	WarningThreshold  int `json:"warningThreshold,omitempty"`
+	CriticalThreshold int `json:"criticalThreshold,omitempty"`
+	// Reclaim unused space of thin volumes automatically
+	AutoReclaim     *bool  `json:"autoReclaim,omitempty"`
+	ReclaimPriority string `json:"reclaimPriority,omitempty"` // BgPriorityHigh, BgPriorityMedium or BgPriorityLow
+}
+
+// OversubscriptionPolicy limits the ratio of subscribed size to capacity of thin pools
+type OversubscriptionPolicy struct {
+	// MaxRatio is the maximum ratio of all pools, e.g. 3 for 300%. 0 means no limit.
+	MaxRatio float64
+	// PoolMaxRatio overrides MaxRatio of certain pools
+	PoolMaxRatio map[string]float64
+}
+
+func (p *OversubscriptionPolicy) maxRatio(poolId string) float64 {
+	if ratio, ok := p.PoolMaxRatio[poolId]; ok {
+		return ratio
+	}
+	return p.MaxRatio
+}
+
+// GetThinSetting get the thin-provisioning thresholds and reclamation setting of a pool
+func (v *PoolOp) GetThinSetting(ctx context.Context, poolId string) (*ThinProvisioningSetting, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/pools/"+poolId+"/thinProvisioning", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := ThinProvisioningSetting{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetThinSetting set the thin-provisioning thresholds and reclamation setting of a pool. Empty fields are unchanged.
+func (v *PoolOp) SetThinSetting(ctx context.Context, poolId string, param *ThinProvisioningSetting) (*ThinProvisioningSetting, error) {
+	if param.WarningThreshold < 0 || param.WarningThreshold > 100 || param.CriticalThreshold < 0 || param.CriticalThreshold > 100 {
+		return nil, fmt.Errorf("thresholds should be between 0 and 100")
+	}
+	if param.WarningThreshold > 0 && param.CriticalThreshold > 0 && param.WarningThreshold >= param.CriticalThreshold {
+		return nil, fmt.Errorf("warning threshold %d should be lower than critical threshold %d", param.WarningThreshold, param.CriticalThreshold)
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/storage/pools/"+poolId+"/thinProvisioning", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := ThinProvisioningSetting{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetOversubscriptionPolicy enables the oversubscription check of thin pools in CreateVolume
+func (v *VolumeOp) SetOversubscriptionPolicy(policy *OversubscriptionPolicy) {
+	v.policy = policy
+}
+
+// OversubscriptionRatio returns the ratio of the total size of volumes in a pool to its capacity
+func (v *VolumeOp) OversubscriptionRatio(ctx context.Context, poolId string) (float64, error) {
+	pool, err := NewPool(v.client).ListPoolByID(ctx, poolId)
+	if err != nil {
+		return 0, err
+	}
+	vols, err := v.ListVolumesByPoolID(ctx, poolId)
+	if err != nil {
+		return 0, err
+	}
+
+	return subscriptionRatio(pool, *vols, 0), nil
+}
+
+// CheckOversubscription returns ErrOversubscribed if a new volume of volsize in a thin pool
+// would exceed the oversubscription policy. Thick pools are never checked.
+// volsize is in the unit of VolumeData.TotalSize, the same as CreateVolume.
+func (v *VolumeOp) CheckOversubscription(ctx context.Context, poolId string, volsize uint64) error {
+	if v.policy == nil {
+		return nil
+	}
+	max := v.policy.maxRatio(poolId)
+	if max <= 0 {
+		return nil
+	}
+
+	pool, err := NewPool(v.client).ListPoolByID(ctx, poolId)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(pool.Provision, ProvisionThin) {
+		return nil
+	}
+	vols, err := v.ListVolumesByPoolID(ctx, poolId)
+	if err != nil {
+		return err
+	}
+
+	if ratio := subscriptionRatio(pool, *vols, volsize); ratio > max {
+		return fmt.Errorf("%w: pool %s would be %.2f, max %.2f", ErrOversubscribed, poolId, ratio, max)
+	}
+	return nil
+}
+
+// Ratio of the total size of volumes plus volsize to the pool capacity
+func subscriptionRatio(pool *PoolData, vols []VolumeData, volsize uint64) float64 {
+	subscribed := volsize
+	for _, vol := range vols {
+		subscribed += vol.TotalSize
+	}
+	if pool.TotalSize == 0 {
+		if subscribed == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return float64(subscribed) / float64(pool.TotalSize)
+}
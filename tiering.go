@@ -0,0 +1,158 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Tiering policies of a volume in an auto-tiering pool
+const (
+	TieringAuto         = "AUTO"
+	TieringHighest      = "HIGHEST"
+	TieringLowest       = "LOWEST"
+	TieringStartHighest = "START_HIGHEST"
+)
+
+// Relocation rates of auto-tiering
+const (
+	RelocationFast   = "FAST"
+	RelocationMedium = "MEDIUM"
+	RelocationSlow   = "SLOW"
+)
+
+// Relocation schedule modes of auto-tiering
+const (
+	RelocationManual = "MANUAL"
+	RelocationDaily  = "DAILY"
+	RelocationWeekly = "WEEKLY"
+)
+
+// return value of GET /rest/v2/storage/pools/_poolId/tiers
+type TierData struct {
+	Level     int    `json:"level"` // 0 is the highest tier
+	Name      string `json:"name"`  // e.g. SSD, SAS, NL-SAS
+	RaidLevel string `json:"raidLevel"`
+	TotalSize uint64 `json:"totalSize"`
+	UsedSize  uint64 `json:"usedSize"`
+	FreeSize  uint64 `json:"freeSize"`
+	// Data to be moved up or down by the next relocation
+	MoveUpSize   uint64 `json:"moveUpSize"`
+	MoveDownSize uint64 `json:"moveDownSize"`
+}
+
+// return value of GET /rest/v2/storage/pools/_poolId/tiering
+// PATCH /rest/v2/storage/pools/_poolId/tiering
+type TieringSetting struct {
+	Mode string   `json:"mode,omitempty"` // MANUAL, DAILY or WEEKLY
+	Time string   `json:"time,omitempty"` // HH:MM
+	Days []string `json:"days,omitempty"` // MON..SUN for WEEKLY
+	Rate string   `json:"rate,omitempty"`
+}
+
+// return value of GET /rest/v2/storage/pools/_poolId/tiering/relocation
+type RelocationStatus struct {
+	Running   bool   `json:"running"`
+	Progress  int    `json:"progress"`
+	StartTime int64  `json:"startTime"`
+	MovedSize uint64 `json:"movedSize"`
+}
+
+// ListTiers list the tiers of an auto-tiering pool
+func (v *PoolOp) ListTiers(ctx context.Context, poolId string) (*[]TierData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/pools/"+poolId+"/tiers", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []TierData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetTieringSetting get the relocation schedule and rate of a pool
+func (v *PoolOp) GetTieringSetting(ctx context.Context, poolId string) (*TieringSetting, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/pools/"+poolId+"/tiering", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := TieringSetting{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetTieringSetting set the relocation schedule and rate of a pool. Empty fields are unchanged.
+func (v *PoolOp) SetTieringSetting(ctx context.Context, poolId string, param *TieringSetting) (*TieringSetting, error) {
+	if param.Mode == RelocationWeekly && len(param.Days) == 0 {
+		return nil, fmt.Errorf("weekly relocation needs days")
+	}
+
+	rawdata, _ := json.Marshal(param)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/storage/pools/"+poolId+"/tiering", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := TieringSetting{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetRelocation get the relocation status of a pool
+func (v *PoolOp) GetRelocation(ctx context.Context, poolId string) (*RelocationStatus, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/pools/"+poolId+"/tiering/relocation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := RelocationStatus{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// StartRelocation starts a relocation of a pool now
+// POST /rest/v2/storage/pools/_poolId/tiering/relocation
+func (v *PoolOp) StartRelocation(ctx context.Context, poolId string) (*RelocationStatus, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/storage/pools/"+poolId+"/tiering/relocation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := RelocationStatus{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// StopRelocation stops the running relocation of a pool
+// DELETE /rest/v2/storage/pools/_poolId/tiering/relocation
+func (v *PoolOp) StopRelocation(ctx context.Context, poolId string) error {
+
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, "/rest/v2/storage/pools/"+poolId+"/tiering/relocation", nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Tokens expiring within this margin are not reused from a token store
+const tokenExpiryMargin = 30 * time.Second
+
+// CachedToken is the persisted form of AuthRes
+type CachedToken struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpireAt     time.Time `json:"expireAt"`
+}
+
+// TokenStore persists access tokens across processes.
+// Load returns nil without error if there is no token for the key.
+type TokenStore interface {
+	Load(key string) (*CachedToken, error)
+	Save(key string, token *CachedToken) error
+	Delete(key string) error
+}
+
+// FileTokenStore keeps encrypted tokens in a directory readable only by the owner.
+// Each array, user and scopes is stored in its own file, encrypted with AES-256-GCM.
+type FileTokenStore struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// NewFileTokenStore returns a token store under dir. The encryption key is derived from secret.
+func NewFileTokenStore(dir string, secret []byte) (*FileTokenStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("token store: empty secret")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+
+	return &FileTokenStore{dir: dir, aead: aead}, nil
+}
+
+func (s *FileTokenStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".token")
+}
+
+// Load reads and decrypts the token of the key
+func (s *FileTokenStore) Load(key string) (*CachedToken, error) {
+	name := s.path(key)
+	fi, err := os.Stat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+	if fi.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("token store: %s is accessible by other users (mode %v)", name, fi.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("token store: %s is corrupted", name)
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("token store: decrypt %s failed: %v", name, err)
+	}
+
+	token := CachedToken{}
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+
+	return &token, nil
+}
+
+// Save encrypts and writes the token of the key
+func (s *FileTokenStore) Save(key string, token *CachedToken) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("token store: %v", err)
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("token store: %v", err)
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, []byte(key))
+
+	// Write to a temporary file then rename, so that concurrent readers never see a partial file.
+	f, err := os.CreateTemp(s.dir, ".token-*")
+	if err != nil {
+		return fmt.Errorf("token store: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return fmt.Errorf("token store: %v", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("token store: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("token store: %v", err)
+	}
+	if err := os.Rename(f.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("token store: %v", err)
+	}
+
+	return nil
+}
+
+// Delete removes the token of the key
+func (s *FileTokenStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("token store: %v", err)
+	}
+
+	return nil
+}
+
+// Valid reports whether the access token can still be used
+func (t *CachedToken) Valid() bool {
+	return t != nil && t.AccessToken != "" && time.Now().Add(tokenExpiryMargin).Before(t.ExpireAt)
+}
+
+func newCachedToken(res *AuthRes, refreshToken string) *CachedToken {
+	if res.RefreshToken != "" {
+		refreshToken = res.RefreshToken
+	}
+
+	return &CachedToken{
+		AccessToken:  res.AccessToken,
+		RefreshToken: refreshToken,
+		ExpireAt:     time.Now().Add(time.Duration(res.ExpireTime) * time.Second),
+	}
+}
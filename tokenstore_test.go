@@ -0,0 +1,102 @@
+package goqsan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tokens")
+	store, err := NewFileTokenStore(dir, []byte("secret"))
+	if err != nil {
+		t.Fatalf("NewFileTokenStore failed: %v", err)
+	}
+	if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm() != 0700 {
+		t.Fatalf("token directory mode is %v: %v", fi.Mode().Perm(), err)
+	}
+
+	key := "https://10.0.0.1|admin|csi.readwrite"
+	if token, err := store.Load(key); token != nil || err != nil {
+		t.Fatalf("Load of missing token returned %+v, %v", token, err)
+	}
+
+	saved := &CachedToken{AccessToken: "AT", RefreshToken: "RT", ExpireAt: time.Now().Add(time.Hour).Round(0)}
+	if err := store.Save(key, saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	token, err := store.Load(key)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if token.AccessToken != saved.AccessToken || token.RefreshToken != saved.RefreshToken || !token.ExpireAt.Equal(saved.ExpireAt) {
+		t.Fatalf("Load returned %+v, saved %+v", token, saved)
+	}
+
+	name := store.path(key)
+	fi, err := os.Stat(name)
+	if err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("token file mode is %v: %v", fi.Mode().Perm(), err)
+	}
+
+	// The token is bound to its key and secret
+	if _, err := store.Load(key + "x"); err != nil {
+		t.Fatalf("Load of another key failed: %v", err)
+	}
+	other, _ := NewFileTokenStore(dir, []byte("other secret"))
+	if _, err := other.Load(key); err == nil {
+		t.Fatalf("Load with another secret should fail")
+	}
+
+	// Files readable by group or others are refused
+	for _, mode := range []os.FileMode{0640, 0604, 0660} {
+		if err := os.Chmod(name, mode); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Load(key); err == nil {
+			t.Fatalf("Load of file with mode %v should fail", mode)
+		}
+	}
+	os.Chmod(name, 0600)
+
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if token, err := store.Load(key); token != nil || err != nil {
+		t.Fatalf("Load after Delete returned %+v, %v", token, err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete of missing token failed: %v", err)
+	}
+
+	if _, err := NewFileTokenStore(dir, nil); err == nil {
+		t.Fatalf("NewFileTokenStore without secret should fail")
+	}
+}
+
+func TestCachedTokenValid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		token *CachedToken
+		valid bool
+	}{
+		{"nil", nil, false},
+		{"no access token", &CachedToken{ExpireAt: now.Add(time.Hour)}, false},
+		{"expired", &CachedToken{AccessToken: "AT", ExpireAt: now.Add(-time.Second)}, false},
+		{"within margin", &CachedToken{AccessToken: "AT", ExpireAt: now.Add(tokenExpiryMargin - time.Second)}, false},
+		{"beyond margin", &CachedToken{AccessToken: "AT", ExpireAt: now.Add(tokenExpiryMargin + 5*time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.token.Valid(); got != tt.valid {
+			t.Errorf("%s: Valid() = %t, want %t", tt.name, got, tt.valid)
+		}
+	}
+
+	token := newCachedToken(&AuthRes{AccessToken: "AT", ExpireTime: 3600}, "old RT")
+	if token.RefreshToken != "old RT" || !token.Valid() {
+		t.Errorf("newCachedToken without refresh token returned %+v", token)
+	}
+}
@@ -0,0 +1,243 @@
+// @2022 QSAN Inc. All rights reserved
+
+package goqsan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrPasswordPolicy matches every PasswordPolicyError by errors.Is
+var ErrPasswordPolicy = errors.New("password policy violation")
+
+// UserOp handles user account related methods of the QSAN storage.
+type UserOp struct {
+	client *AuthClient
+}
+
+// UserRole is the role of a user account
+type UserRole string
+
+const (
+	RoleAdmin    UserRole = "ADMIN"
+	RoleOperator UserRole = "OPERATOR"
+	RoleMonitor  UserRole = "MONITOR"
+)
+
+// Error codes of password policy violations
+var passwordPolicyCodes = map[int]string{
+	10701: "password too short",
+	10702: "password too weak",
+	10703: "password reused",
+	10704: "password contains user name",
+	10705: "incorrect old password",
+}
+
+// PasswordPolicyError is returned when a password is rejected by the password policy
+type PasswordPolicyError struct {
+	Reason string
+	Err    *RestError
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrPasswordPolicy, e.Reason, e.Err)
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordPolicy
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return e.Err
+}
+
+// Convert a RestError of password policy to PasswordPolicyError
+func passwordError(err error) error {
+	resterr, ok := err.(*RestError)
+	if !ok {
+		return err
+	}
+	if reason, ok := passwordPolicyCodes[resterr.ErrResp.Error.Code]; ok {
+		return &PasswordPolicyError{Reason: reason, Err: resterr}
+	}
+	return err
+}
+
+// return value of GET /rest/v2/system/users
+type UserData struct {
+	Name    string   `json:"name"`
+	Role    UserRole `json:"role"`
+	Groups  []string `json:"groups"`
+	Email   string   `json:"email"`
+	Enabled bool     `json:"enabled"`
+}
+
+// POST /rest/v2/system/users
+type UserCreateOptions struct {
+	Name     string   `json:"name"`
+	Password string   `json:"password"`
+	Role     UserRole `json:"role"`
+	Groups   []string `json:"groups,omitempty"`
+	Email    string   `json:"email,omitempty"`
+}
+
+// PATCH /rest/v2/system/users/_userName
+type UserModifyOptions struct {
+	Role    UserRole `json:"role,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Email   string   `json:"email,omitempty"`
+	Enabled *bool    `json:"enabled,omitempty"`
+}
+
+// return value of GET /rest/v2/system/users/_userName/password
+type PasswordStatus struct {
+	LastChangeTime int64 `json:"lastChangeTime"`
+	ExpireTime     int64 `json:"expireTime"` // 0 if never expires
+	Expired        bool  `json:"expired"`
+}
+
+// NewUser returns user operation
+func NewUser(client *AuthClient) *UserOp {
+	return &UserOp{client}
+}
+
+// ListUsers list all local users
+func (v *UserOp) ListUsers(ctx context.Context) (*[]UserData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/users", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []UserData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListUserByName list certain user
+func (v *UserOp) ListUserByName(ctx context.Context, userName string) (*UserData, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/users/"+userName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := UserData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CreateUser create a local user
+func (v *UserOp) CreateUser(ctx context.Context, userName, passwd string, options *UserCreateOptions) (*UserData, error) {
+
+	options.Name = userName
+	options.Password = passwd
+	rawdata, _ := json.Marshal(options)
+	req, err := v.client.NewRequest(ctx, http.MethodPost, "/rest/v2/system/users", string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := UserData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, passwordError(err)
+	}
+	return &res, nil
+}
+
+// DeleteUser delete a local user
+func (v *UserOp) DeleteUser(ctx context.Context, userName string) error {
+	req, err := v.client.NewRequest(ctx, http.MethodDelete, "/rest/v2/system/users/"+userName, nil)
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Patch certain user
+func (v *UserOp) ModifyUser(ctx context.Context, userName string, options *UserModifyOptions) (*UserData, error) {
+	rawdata, _ := json.Marshal(options)
+	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/system/users/"+userName, string(rawdata))
+	if err != nil {
+		return nil, err
+	}
+
+	res := UserData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Get password expiry status of certain user
+// GET /rest/v2/system/users/_userName/password
+func (v *UserOp) GetPasswordStatus(ctx context.Context, userName string) (*PasswordStatus, error) {
+
+	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/system/users/"+userName+"/password", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := PasswordStatus{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ChangePassword change the password of certain user. oldPasswd is only required for the login user.
+// PUT /rest/v2/system/users/_userName/password
+func (v *UserOp) ChangePassword(ctx context.Context, userName, oldPasswd, newPasswd string) error {
+	m := map[string]string{
+		"newPassword": newPasswd,
+	}
+	if oldPasswd != "" {
+		m["oldPassword"] = oldPasswd
+	}
+	rawdata, _ := json.Marshal(m)
+	req, err := v.client.NewRequest(ctx, http.MethodPut, "/rest/v2/system/users/"+userName+"/password", string(rawdata))
+	if err != nil {
+		return err
+	}
+
+	res := EmptyData{}
+	if err := v.client.SendRequest(ctx, req, &res); err != nil {
+		return passwordError(err)
+	}
+
+	return nil
+}
+
+// ChangeOwnPassword change the password of the login user of the AuthClient.
+// If the client uses StaticCredentials, it is updated to login with the new password later.
+// Clients with credential-encoded scopes should be created by GetAuthClientWithScopes,
+// so that the scopes also follow the new password.
+func (v *UserOp) ChangeOwnPassword(ctx context.Context, newPasswd string) error {
+	creds, err := v.client.creds.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieve credentials failed: %v", err)
+	}
+
+	if err := v.ChangePassword(ctx, creds.User, creds.Password, newPasswd); err != nil {
+		return err
+	}
+
+	if sc, ok := v.client.creds.(*StaticCredentials); ok {
+		sc.SetPassword(newPasswd)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package goqsan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestUser(t *testing.T) {
+	fmt.Println("------------TestUser--------------")
+	ctx = context.Background()
+
+	listUserTest(t)
+
+	now := time.Now()
+	timeStamp := now.Format("20060102150405")
+	createDeleteUserTest(t, "gtuser"+timeStamp[8:])
+}
+
+func listUserTest(t *testing.T) {
+	fmt.Println("listUserTest Enter")
+
+	users, err := testConf.userOp.ListUsers(ctx)
+	if err != nil {
+		t.Fatalf("ListUsers failed: %v", err)
+	}
+	fmt.Printf("  ListUsers cnt: %d\n  %+v\n", len(*users), users)
+
+	status, err := testConf.userOp.GetPasswordStatus(ctx, testConf.user)
+	if err != nil {
+		t.Fatalf("GetPasswordStatus(%s) failed: %v", testConf.user, err)
+	}
+	fmt.Printf("  GetPasswordStatus(%s): %+v\n", testConf.user, status)
+
+	fmt.Println("listUserTest Leave")
+}
+
+func createDeleteUserTest(t *testing.T, userName string) {
+	fmt.Printf("createDeleteUserTest Enter (%s)\n", userName)
+
+	// A too simple password should be rejected by the password policy
+	_, err := testConf.userOp.CreateUser(ctx, userName, "1", &UserCreateOptions{Role: RoleMonitor})
+	if err == nil {
+		t.Fatalf("CreateUser with weak password should fail")
+	}
+	if !errors.Is(err, ErrPasswordPolicy) {
+		fmt.Printf("  CreateUser with weak password returned non-policy error: %v\n", err)
+	}
+
+	user, err := testConf.userOp.CreateUser(ctx, userName, "Gt-passw0rd-1234", &UserCreateOptions{Role: RoleMonitor})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	fmt.Printf("  A user was created. %+v\n", user)
+
+	user, err = testConf.userOp.ModifyUser(ctx, userName, &UserModifyOptions{Role: RoleOperator})
+	if err != nil {
+		t.Fatalf("ModifyUser failed: %v", err)
+	}
+	if user.Role != RoleOperator {
+		t.Fatalf("ModifyUser change Role failed: %s", user.Role)
+	}
+
+	if err := testConf.userOp.ChangePassword(ctx, userName, "", "Gt-passw0rd-5678"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	if err := testConf.userOp.DeleteUser(ctx, userName); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	fmt.Printf("  A user was deleted. %s\n", userName)
+
+	fmt.Println("createDeleteUserTest Leave")
+}
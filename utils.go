@@ -2,7 +2,10 @@ package goqsan
 
 import (
 	"bytes"
+	"context"
 	"crypto/aes"
+	"errors"
+	"time"
 )
 
 func PKCS7Padding(ciphertext []byte, blockSize int) []byte {
@@ -11,16 +14,22 @@ func PKCS7Padding(ciphertext []byte, blockSize int) []byte {
 	return append(ciphertext, padtext...)
 }
 
-func PKCS7UnPadding(origData []byte) []byte {
+func PKCS7UnPadding(origData []byte) ([]byte, error) {
 	length := len(origData)
+	if length == 0 {
+		return nil, errors.New("pkcs7: empty data")
+	}
 	unpadding := int(origData[length-1])
-	return origData[:(length - unpadding)]
+	if unpadding == 0 || unpadding > length {
+		return nil, errors.New("pkcs7: invalid padding")
+	}
+	return origData[:(length - unpadding)], nil
 }
 
-func AESECBEncrypt(plaintext, key []byte) []byte {
+func AESECBEncrypt(plaintext, key []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	data := PKCS7Padding(plaintext, block.BlockSize())
 	ciphertext := make([]byte, len(data))
@@ -30,22 +39,36 @@ func AESECBEncrypt(plaintext, key []byte) []byte {
 		block.Encrypt(ciphertext[bs:be], data[bs:be])
 	}
 
-	return ciphertext
+	return ciphertext, nil
 }
 
-func AESECBDecrypt(ciphertext, key []byte) []byte {
+func AESECBDecrypt(ciphertext, key []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
-	decrypted := make([]byte, len(ciphertext))
 	size := block.BlockSize()
+	if len(ciphertext)%size != 0 {
+		return nil, errors.New("aes: ciphertext is not a multiple of the block size")
+	}
+	decrypted := make([]byte, len(ciphertext))
 
 	for bs, be := 0, size; bs < len(ciphertext); bs, be = bs+size, be+size {
 		block.Decrypt(decrypted[bs:be], ciphertext[bs:be])
 	}
 
-	plaintext := PKCS7UnPadding(decrypted)
+	return PKCS7UnPadding(decrypted)
+}
+
+// sleepContext waits for d, or returns the error of ctx if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
 
-	return plaintext
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
@@ -12,6 +12,8 @@ import (
 // VolumeOp handles volume related methods of the QSAN storage.
 type VolumeOp struct {
 	client *AuthClient
+	caps   *Capabilities
+	policy *OversubscriptionPolicy
 }
 
 type VolumeMetadata struct {
@@ -45,6 +47,7 @@ type VolumeData struct {
 	TargetResponseTime    uint64 `json:"targetResponseTime"`
 	MaxIops               uint64 `json:"maxIops"`
 	MaxThroughtput        uint64 `json:"maxThroughtput"`
+	TieringPolicy         string `json:"tieringPolicy"`
 	Tags                  struct {
 		Wwn  string `json:"wwn"`
 		Type string `json:"type"`
@@ -61,6 +64,7 @@ type VolumeCreateOptions struct {
 	BgIoPriority    string         `json:"bgIoPriority,omitempty"`
 	CacheMode       string         `json:"cacheMode,omitempty"`
 	EnableReadAhead *bool          `json:"enableReadAhead,omitempty"`
+	TieringPolicy   string         `json:"tieringPolicy,omitempty"`
 	Metadata        VolumeMetadata `json:"metadata,omitempty"`
 }
 
@@ -84,6 +88,7 @@ type VolumeModifyOptions struct {
 	BgIoPriority    string         `json:"bgIoPriority,omitempty"`
 	CacheMode       string         `json:"cacheMode,omitempty"`
 	EnableReadAhead *bool          `json:"enableReadAhead,omitempty"`
+	TieringPolicy   string         `json:"tieringPolicy,omitempty"`
 	Tags            Tag            `json:"tags,omitempty"`
 	Metadata        VolumeMetadata `json:"metadata,omitempty"`
 }
@@ -146,7 +151,12 @@ type SnaphshotData struct {
 
 // NewVolume returns volume operation
 func NewVolume(client *AuthClient) *VolumeOp {
-	return &VolumeOp{client}
+	return &VolumeOp{client: client}
+}
+
+// SetCapabilities enables feature checks before requests are sent
+func (v *VolumeOp) SetCapabilities(caps *Capabilities) {
+	v.caps = caps
 }
 
 // ListVolumes list all volumes
@@ -200,6 +210,9 @@ func (v *VolumeOp) ListVolumesByPoolID(ctx context.Context, poolId string) (*[]V
 
 // CreateVolume create a volume on a storage container
 func (v *VolumeOp) CreateVolume(ctx context.Context, poolId, volname string, volsize uint64, options *VolumeCreateOptions) (*VolumeData, error) {
+	if err := v.CheckOversubscription(ctx, poolId, volsize); err != nil {
+		return nil, err
+	}
 
 	options.PoolID = poolId
 	options.Name = volname
@@ -234,6 +247,12 @@ func (v *VolumeOp) DeleteVolume(ctx context.Context, volId string) error {
 }
 
 func (v *VolumeOp) ModifyVolume(ctx context.Context, volId string, options *VolumeModifyOptions) (*VolumeData, error) {
+	if options.TargetResponseTime != 0 || options.MaxIops != 0 || options.MaxThroughtput != 0 {
+		if err := v.caps.Require(FeatureQoS); err != nil {
+			return nil, err
+		}
+	}
+
 	rawdata, _ := json.Marshal(options)
 	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/storage/block/volumes/"+volId, string(rawdata))
 	if err != nil {
@@ -249,6 +268,9 @@ func (v *VolumeOp) ModifyVolume(ctx context.Context, volId string, options *Volu
 }
 
 func (v *VolumeOp) GetQoS(ctx context.Context) (*QoSData, error) {
+	if err := v.caps.Require(FeatureQoS); err != nil {
+		return nil, err
+	}
 
 	req, err := v.client.NewRequest(ctx, http.MethodGet, "/rest/v2/storage/qos/volumes", nil)
 	if err != nil {
@@ -263,6 +285,9 @@ func (v *VolumeOp) GetQoS(ctx context.Context) (*QoSData, error) {
 }
 
 func (v *VolumeOp) SetQoS(ctx context.Context, qosEnable bool, qosRule string) (*QoSData, error) {
+	if err := v.caps.Require(FeatureQoS); err != nil {
+		return nil, err
+	}
 
 	options := QoSData{}
 	options.EnableQos = qosEnable
@@ -369,6 +394,11 @@ func (v *VolumeOp) GetSnapshot(ctx context.Context, volId, snapId string) (*Snap
 // Patch certain volume snapshot
 // PATCH /rest/v2/backup/snapshot/targets/_volumeID/snapshots/_snapshotID
 func (v *VolumeOp) ModifySnapshot(ctx context.Context, volId, snapId string, options *SnaphshotOptions) (*[]SnaphshotData, error) {
+	if options.Expose.Enable {
+		if err := v.caps.Require(FeatureSnapshotExpose); err != nil {
+			return nil, err
+		}
+	}
 
 	rawdata, _ := json.Marshal(options)
 	req, err := v.client.NewRequest(ctx, http.MethodPatch, "/rest/v2/backup/snapshot/targets/"+volId+"/snapshots/"+snapId, string(rawdata))
@@ -516,6 +546,10 @@ func (v *VolumeOp) SetMetadata(ctx context.Context, volId, metastatus, metatype
 }
 
 func (v *VolumeOp) Clone(ctx context.Context, volId, newVolName, poolId string) (*VolumeData, error) {
+	if err := v.caps.Require(FeatureClone); err != nil {
+		return nil, err
+	}
+
 	m := map[string]string{
 		"volumeName": newVolName,
 		"poolID":     poolId,
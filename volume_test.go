@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	b64 "encoding/base64"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -82,6 +84,7 @@ func TestVolume(t *testing.T) {
 	volName = "gt-clonevol-" + timeStamp
 	cloneTest(t, testConf.poolId, volName, 10240, &paramCVol)
 
+	oversubscriptionTest(t, testConf.poolId)
 }
 
 func listVolumeTest(t *testing.T) {
@@ -643,3 +646,36 @@ func cloneTest(t *testing.T, poolId, volname string, volsize uint64, optionsV *V
 
 	fmt.Println("cloneTest Leave")
 }
+
+func oversubscriptionTest(t *testing.T, poolId string) {
+	fmt.Println("oversubscriptionTest Enter")
+
+	pool, err := testConf.poolOp.ListPoolByID(ctx, poolId)
+	if err != nil {
+		t.Fatalf("ListPoolByID failed: %v", err)
+	}
+	ratio, err := testConf.volumeOp.OversubscriptionRatio(ctx, poolId)
+	if err != nil {
+		t.Fatalf("OversubscriptionRatio failed: %v", err)
+	}
+	fmt.Printf("  Pool %s (%s) oversubscription ratio: %.2f\n", poolId, pool.Provision, ratio)
+
+	// Use another VolumeOp to keep the policy away from other tests
+	volumeOp := NewVolume(testConf.volumeOp.client)
+	volumeOp.SetOversubscriptionPolicy(&OversubscriptionPolicy{MaxRatio: 100, PoolMaxRatio: map[string]float64{poolId: 0.0001}})
+	err = volumeOp.CheckOversubscription(ctx, poolId, pool.TotalSize)
+	if strings.EqualFold(pool.Provision, ProvisionThin) {
+		if !errors.Is(err, ErrOversubscribed) {
+			t.Fatalf("CheckOversubscription of thin pool %s should fail with ErrOversubscribed: %v", poolId, err)
+		}
+		setting, err := testConf.poolOp.GetThinSetting(ctx, poolId)
+		if err != nil {
+			t.Fatalf("GetThinSetting failed: %v", err)
+		}
+		fmt.Printf("  GetThinSetting: %+v\n", setting)
+	} else if err != nil {
+		t.Fatalf("CheckOversubscription of thick pool %s should pass: %v", poolId, err)
+	}
+
+	fmt.Println("oversubscriptionTest Leave")
+}